@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"strconv"
 	"strings"
 
 	"github.com/Gage-McGuire/kev/token"
@@ -51,6 +52,28 @@ type ReturnStatement struct {
 	ReturnValue Expression  // the value being returned
 }
 
+// Represents an import statement, which either binds the
+// whole module to Alias, e.g. import "lib.kev" as lib,
+// or binds the listed exports, e.g. import { a, b as c } from "lib.kev"
+type ImportStatement struct {
+	Token    token.Token      // the 'import' token
+	Path     string           // the path of the module being imported
+	Alias    *Identifier      // the name the module is bound to, nil for selective imports
+	Bindings []*ImportBinding // the exports being imported, empty unless selective
+}
+
+// Represents a single export listed in a selective import
+type ImportBinding struct {
+	Name  *Identifier // the name of the export
+	Alias *Identifier // the name it's bound to, the same as Name without 'as'
+}
+
+// Represents an export statement, e.g. export var x = 1
+type ExportStatement struct {
+	Token     token.Token   // the 'export' token
+	Statement *VarStatement // the var statement being exported
+}
+
 // Represents an expression statement
 type ExpressionStatement struct {
 	Token      token.Token // the first token of the expression
@@ -63,6 +86,12 @@ type IntegerLiteral struct {
 	Value int64       // the value of the integer
 }
 
+// Represents a float literal
+type FloatLiteral struct {
+	Token token.Token // the token.FLOAT token
+	Value float64     // the value of the float
+}
+
 // Represents a string literal
 type StringLiteral struct {
 	Token token.Token
@@ -79,6 +108,7 @@ type ArrayLiteral struct {
 type HashLiteral struct {
 	Token token.Token
 	Pairs map[Expression]Expression
+	Keys  []Expression // the keys of Pairs in the order they were written
 }
 
 // Represents a prefix expression with a prefix operator
@@ -102,6 +132,11 @@ type Boolean struct {
 	Value bool
 }
 
+// Represents the null literal
+type NullLiteral struct {
+	Token token.Token // the token.NULL token
+}
+
 // Represents a if expression
 type IfExpression struct {
 	Token       token.Token // the 'if' token
@@ -128,13 +163,33 @@ type CallExpression struct {
 	Token     token.Token  // the '(' token
 	Function  Expression   // the function being called
 	Arguments []Expression // the arguments being passed to the function
+	Optional  bool         // true for f?.(x), which skips the call when f is null
 }
 
 // Represents an index expression
 type IndexExpression struct {
-	Token token.Token // the '[' token
-	Left  Expression  // the left expression
-	Index Expression  // the index expression
+	Token    token.Token // the '[' token
+	Left     Expression  // the left expression
+	Index    Expression  // the index expression
+	Optional bool        // true for a?.[k], which skips the index when a is null
+}
+
+// Represents a member expression, e.g. lib.name
+type MemberExpression struct {
+	Token    token.Token // the '.' or '?.' token
+	Object   Expression  // the expression the member belongs to
+	Property *Identifier // the name of the member
+	Optional bool        // true for a?.name, which skips the lookup when a is null
+}
+
+// Represents a slice expression, e.g. arr[1:3].
+// Start and End are nil when they are left out
+type SliceExpression struct {
+	Token    token.Token // the '[' token
+	Left     Expression  // the left expression
+	Start    Expression  // the start of the slice, inclusive
+	End      Expression  // the end of the slice, exclusive
+	Optional bool        // true for a?.[i:j], which skips the slice when a is null
 }
 
 // variable
@@ -155,6 +210,18 @@ func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
 
+// import
+func (is *ImportStatement) statementNode() {}
+func (is *ImportStatement) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+// export
+func (es *ExportStatement) statementNode() {}
+func (es *ExportStatement) TokenLiteral() string {
+	return es.Token.Literal
+}
+
 // expression
 func (es *ExpressionStatement) statementNode() {}
 func (es *ExpressionStatement) TokenLiteral() string {
@@ -167,6 +234,12 @@ func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
 
+// float
+func (fl *FloatLiteral) expressionNode() {}
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
 // string
 func (sl *StringLiteral) expressionNode() {}
 func (sl *StringLiteral) TokenLiteral() string {
@@ -203,6 +276,12 @@ func (b *Boolean) TokenLiteral() string {
 	return b.Token.Literal
 }
 
+// null
+func (nl *NullLiteral) expressionNode() {}
+func (nl *NullLiteral) TokenLiteral() string {
+	return nl.Token.Literal
+}
+
 // if
 func (ie *IfExpression) expressionNode() {}
 func (ie *IfExpression) TokenLiteral() string {
@@ -233,6 +312,18 @@ func (ie *IndexExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+// member
+func (me *MemberExpression) expressionNode() {}
+func (me *MemberExpression) TokenLiteral() string {
+	return me.Token.Literal
+}
+
+// slice
+func (se *SliceExpression) expressionNode() {}
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
 // gets the root node of the AST
 func (p *Program) TokenLiteral() string {
 	if len(p.Statements) > 0 {
@@ -279,6 +370,39 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// converts the import statement to a string
+func (is *ImportStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(is.TokenLiteral() + " ")
+	if is.Alias != nil {
+		out.WriteString(strconv.Quote(is.Path))
+		out.WriteString(" as ")
+		out.WriteString(is.Alias.String())
+	} else {
+		bindings := []string{}
+		for _, b := range is.Bindings {
+			if b.Alias.Value != b.Name.Value {
+				bindings = append(bindings, b.Name.String()+" as "+b.Alias.String())
+			} else {
+				bindings = append(bindings, b.Name.String())
+			}
+		}
+		out.WriteString("{ ")
+		out.WriteString(strings.Join(bindings, ", "))
+		out.WriteString(" } from ")
+		out.WriteString(strconv.Quote(is.Path))
+	}
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// converts the export statement to a string
+func (es *ExportStatement) String() string {
+	return es.TokenLiteral() + " " + es.Statement.String()
+}
+
 // converts the expression statement to a string
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
@@ -297,6 +421,11 @@ func (il *IntegerLiteral) String() string {
 	return il.TokenLiteral()
 }
 
+// converts the float literal to a string
+func (fl *FloatLiteral) String() string {
+	return fl.TokenLiteral()
+}
+
 // converts the string literal to a string
 func (sl *StringLiteral) String() string {
 	return sl.TokenLiteral()
@@ -307,6 +436,11 @@ func (b *Boolean) String() string {
 	return b.TokenLiteral()
 }
 
+// converts the null literal to a string
+func (nl *NullLiteral) String() string {
+	return nl.TokenLiteral()
+}
+
 // converts the array literal to a string
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
@@ -328,8 +462,8 @@ func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.Keys {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 
 	out.WriteString("{")
@@ -418,6 +552,9 @@ func (ce *CallExpression) String() string {
 		args = append(args, a.String())
 	}
 	out.WriteString(ce.Function.String())
+	if ce.Optional {
+		out.WriteString("?.")
+	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(args, ", "))
 	out.WriteString(")")
@@ -430,9 +567,51 @@ func (ie *IndexExpression) String() string {
 
 	out.WriteString("(")
 	out.WriteString(ie.Left.String())
+	if ie.Optional {
+		out.WriteString("?.")
+	}
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
 	out.WriteString("])")
 
 	return out.String()
 }
+
+// converts the slice expression to a string
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	if se.Optional {
+		out.WriteString("?.")
+	}
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// converts the member expression to a string
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(me.Object.String())
+	if me.Optional {
+		out.WriteString("?.")
+	} else {
+		out.WriteString(".")
+	}
+	out.WriteString(me.Property.String())
+	out.WriteString(")")
+
+	return out.String()
+}
@@ -0,0 +1,542 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gage-McGuire/kev/evaluator"
+	"github.com/Gage-McGuire/kev/format"
+	"github.com/Gage-McGuire/kev/lexer"
+	"github.com/Gage-McGuire/kev/object"
+	"github.com/Gage-McGuire/kev/parser"
+	repl "github.com/Gage-McGuire/kev/repl"
+	"github.com/Gage-McGuire/kev/std"
+)
+
+// cli holds the streams the commands read from and write to,
+// so they can be run against buffers in tests
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a kev subcommand, e.g. kev run
+type command struct {
+	name    string // the name it's run with
+	args    string // the arguments it takes, shown in its usage
+	summary string // a one line description, shown in kev help
+	help    string // a longer description, shown in its usage
+
+	// run declares the command's flags on the flag set,
+	// parses the arguments with it and runs the command.
+	// It returns the status kev exits with
+	run func(c *cli, flags *flag.FlagSet, args []string) int
+}
+
+// commands are the subcommands of kev, in the order kev help lists them.
+// They are filled in by init() since help needs to list them
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{
+			name:    "run",
+			args:    "<file|-> [args...]",
+			summary: "run a kev script",
+			help: `Runs the kev script in the file, or the script read from stdin when
+the file is -. The arguments after the file are passed to the script
+in args. kev exits with the status the script passed to exit, or with
+1 when the script can't be parsed or evaluating it ends in an error.`,
+			run: runCommand,
+		},
+		{
+			name:    "repl",
+			args:    "[flags]",
+			summary: "start the interactive prompt",
+			help: `Starts the read-eval-print loop, which evaluates each line typed
+at the prompt and prints the result. Running kev without a command
+starts it too.`,
+			run: replCommand,
+		},
+		{
+			name:    "fmt",
+			args:    "[flags] [paths...]",
+			summary: "format kev source files",
+			help: `Formats the kev files at the paths in the canonical style.
+Directories are searched for .kev files. Without paths, or with the
+path -, the source is read from stdin. The formatted source is printed
+unless -l or -w is given.`,
+			run: fmtCommand,
+		},
+		{
+			name:    "check",
+			args:    "[paths...]",
+			summary: "report parsing errors in kev source files",
+			help: `Parses the kev files at the paths without running them and reports
+every parsing error as <file>: <error>. Directories are searched for
+.kev files. Without paths, or with the path -, the source is read from
+stdin. kev exits with 1 when any file has errors.`,
+			run: checkCommand,
+		},
+		{
+			name:    "test",
+			args:    "[flags] [paths...]",
+			summary: "run kev test files",
+			help: `Runs every file ending in _test.kev at the paths, which default to
+the working directory. Directories are searched recursively. A test
+file passes when running it doesn't end in an error, so it fails at the
+first assert or assertEqual that doesn't hold. The output of passing
+files is only shown with -v. kev exits with 1 when any file fails.`,
+			run: testCommand,
+		},
+		{
+			name:    "version",
+			args:    "",
+			summary: "print the kev version",
+			help:    `Prints the version of kev.`,
+			run:     versionCommand,
+		},
+		{
+			name:    "help",
+			args:    "[command]",
+			summary: "show help for kev or one of its commands",
+			help:    `Shows the usage of kev, or of the command when one is given.`,
+			run:     helpCommand,
+		},
+	}
+}
+
+// run parses the arguments kev was started with, not including
+// the program name, and runs the command they name. It returns
+// the status kev exits with, which is 2 for wrong usage
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	flags := flag.NewFlagSet("kev", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	source := flags.String("e", "", "evaluate the `source` and print the result, passing the remaining arguments to it in args")
+	flags.Usage = func() {
+		c.usage(flags.Output())
+	}
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	evaluate := false
+	flags.Visit(func(f *flag.Flag) {
+		evaluate = evaluate || f.Name == "e"
+	})
+	if evaluate {
+		return repl.RunSource(c.context(flags.Args()), *source, "")
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stdout, banner)
+		repl.RunPrompt(stdin, stdout)
+		return 0
+	}
+
+	cmd := lookupCommand(flags.Arg(0))
+	if cmd == nil {
+		fmt.Fprintf(stderr, "kev: unknown command %q\nRun 'kev help' for usage.\n", flags.Arg(0))
+		return 2
+	}
+	return cmd.run(c, c.flagSet(cmd), flags.Args()[1:])
+}
+
+// usage writes the usage of kev, listing its commands
+func (c *cli) usage(w io.Writer) {
+	fmt.Fprint(w, `kev is a small interpreted language.
+
+usage:
+	kev                        start the repl
+	kev <command> [arguments]  run a command
+	kev -e <source> [args...]  evaluate the source and print the result
+
+commands:
+`)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t%-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(w, "\nRun 'kev help <command>' for more about a command.\n")
+}
+
+// lookupCommand returns the command with the name, or nil when there is none
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// flagSet returns the flag set of the command, whose usage
+// shows the command's arguments, its help and its flags
+func (c *cli) flagSet(cmd *command) *flag.FlagSet {
+	flags := flag.NewFlagSet("kev "+cmd.name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		w := flags.Output()
+		fmt.Fprintf(w, "usage: kev %s\n\n%s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.help)
+		hasFlags := false
+		flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprint(w, "\nflags:\n")
+			flags.PrintDefaults()
+		}
+	}
+	return flags
+}
+
+// parseFlags parses the arguments with the flag set. When parsing
+// stops the command, because of -h or a bad flag, it returns false
+// along with the status kev exits with
+func parseFlags(flags *flag.FlagSet, args []string) (int, bool) {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0, false
+	}
+	if err != nil {
+		return 2, false
+	}
+	return 0, true
+}
+
+// usageError writes the message and the usage of
+// the flag set and returns the status for wrong usage
+func usageError(flags *flag.FlagSet, format string, a ...interface{}) int {
+	fmt.Fprintf(flags.Output(), format+"\n", a...)
+	flags.Usage()
+	return 2
+}
+
+// context returns a context using the cli's
+// streams and passing the args to the script
+func (c *cli) context(args []string) *object.Context {
+	ctx := object.NewContext()
+	ctx.Stdin = c.stdin
+	ctx.Stdout = c.stdout
+	ctx.Stderr = c.stderr
+	ctx.Args = args
+	return ctx
+}
+
+// runCommand runs kev run
+func runCommand(c *cli, flags *flag.FlagSet, args []string) int {
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() == 0 {
+		return usageError(flags, "kev run: no file given")
+	}
+	return repl.RunFile(c.context(flags.Args()[1:]), flags.Arg(0))
+}
+
+// replCommand runs kev repl
+func replCommand(c *cli, flags *flag.FlagSet, args []string) int {
+	quiet := flags.Bool("quiet", false, "don't print the banner")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() != 0 {
+		return usageError(flags, "kev repl: unexpected arguments %v", flags.Args())
+	}
+	if !*quiet {
+		fmt.Fprintln(c.stdout, banner)
+	}
+	repl.RunPrompt(c.stdin, c.stdout)
+	return 0
+}
+
+// fmtCommand runs kev fmt
+func fmtCommand(c *cli, flags *flag.FlagSet, args []string) int {
+	list := flags.Bool("l", false, "list the files whose formatting differs instead of printing them")
+	write := flags.Bool("w", false, "write the formatted source back to the files instead of printing it")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	if isStdin(flags.Args()) {
+		src, err := io.ReadAll(c.stdin)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "kev fmt: %s\n", err)
+			return 1
+		}
+		formatted, err := format.Source(src)
+		if err != nil {
+			reportErrors(c.stderr, "<stdin>", err.Error())
+			return 1
+		}
+		c.stdout.Write(formatted)
+		return 0
+	}
+
+	files, err := kevFiles(flags.Args(), ".kev")
+	if err != nil {
+		fmt.Fprintf(c.stderr, "kev fmt: %s\n", err)
+		return 1
+	}
+	status := 0
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "kev fmt: %s\n", err)
+			status = 1
+			continue
+		}
+		formatted, err := format.Source(src)
+		if err != nil {
+			reportErrors(c.stderr, file, err.Error())
+			status = 1
+			continue
+		}
+
+		changed := !bytes.Equal(src, formatted)
+		if *list && changed {
+			fmt.Fprintln(c.stdout, file)
+		}
+		if *write && changed {
+			if err := os.WriteFile(file, formatted, 0o644); err != nil {
+				fmt.Fprintf(c.stderr, "kev fmt: %s\n", err)
+				status = 1
+			}
+		}
+		if !*list && !*write {
+			c.stdout.Write(formatted)
+		}
+	}
+	return status
+}
+
+// checkCommand runs kev check
+func checkCommand(c *cli, flags *flag.FlagSet, args []string) int {
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	if isStdin(flags.Args()) {
+		src, err := io.ReadAll(c.stdin)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "kev check: %s\n", err)
+			return 1
+		}
+		if errs := parseErrors(src); len(errs) != 0 {
+			reportErrors(c.stderr, "<stdin>", errs...)
+			return 1
+		}
+		return 0
+	}
+
+	files, err := kevFiles(flags.Args(), ".kev")
+	if err != nil {
+		fmt.Fprintf(c.stderr, "kev check: %s\n", err)
+		return 1
+	}
+	status := 0
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "kev check: %s\n", err)
+			status = 1
+			continue
+		}
+		if errs := parseErrors(src); len(errs) != 0 {
+			reportErrors(c.stderr, file, errs...)
+			status = 1
+		}
+	}
+	return status
+}
+
+// testCommand runs kev test
+func testCommand(c *cli, flags *flag.FlagSet, args []string) int {
+	verbose := flags.Bool("v", false, "show the output of passing test files too")
+	withStd := flags.Bool("std", false, "also run the self-tests of the std modules")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := kevFiles(paths, "_test.kev")
+	if err != nil {
+		fmt.Fprintf(c.stderr, "kev test: %s\n", err)
+		return 1
+	}
+
+	passed, failed := 0, 0
+	count := func(ok bool) {
+		if ok {
+			passed++
+		} else {
+			failed++
+		}
+	}
+	if *withStd {
+		for _, name := range std.Names() {
+			if src, ok := std.Tests(name); ok {
+				count(c.runTest("std/"+name, src, "", *verbose))
+			}
+		}
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "kev test: %s\n", err)
+			failed++
+			continue
+		}
+		count(c.runTest(file, src, file, *verbose))
+	}
+
+	if passed+failed == 0 {
+		fmt.Fprintln(c.stdout, "no test files")
+		return 0
+	}
+	fmt.Fprintf(c.stdout, "%d passed, %d failed\n", passed, failed)
+	if failed != 0 {
+		return 1
+	}
+	return 0
+}
+
+// runTest runs the test file and reports whether it passed. The output
+// of the file is only shown when it fails or verbose is set
+func (c *cli) runTest(name string, src []byte, file string, verbose bool) bool {
+	var output bytes.Buffer
+	ctx := c.context(nil)
+	ctx.Stdin = strings.NewReader("")
+	if !verbose {
+		ctx.Stdout = &output
+		ctx.Stderr = &output
+	}
+
+	var failure string
+	l := lexer.New(string(src))
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		failure = "parse error: " + strings.Join(p.Errors(), "; ")
+	} else {
+		env := object.NewEnvironment()
+		env.SetContext(ctx)
+		env.SetFile(file)
+		if errObj, ok := evaluator.Eval(program, env).(*object.Error); ok && (!errObj.Exit || errObj.Code != 0) {
+			failure = errObj.Message
+		}
+	}
+
+	if failure == "" {
+		fmt.Fprintf(c.stdout, "ok   %s\n", name)
+		return true
+	}
+	fmt.Fprintf(c.stdout, "FAIL %s\n", name)
+	for _, line := range strings.Split(strings.TrimRight(output.String(), "\n"), "\n") {
+		if line != "" {
+			fmt.Fprintf(c.stdout, "     %s\n", line)
+		}
+	}
+	fmt.Fprintf(c.stdout, "     %s\n", failure)
+	return false
+}
+
+// versionCommand runs kev version
+func versionCommand(c *cli, flags *flag.FlagSet, args []string) int {
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	fmt.Fprintf(c.stdout, "kev %s\n", version)
+	return 0
+}
+
+// helpCommand runs kev help, which writes the usage of
+// kev or of the command to stdout
+func helpCommand(c *cli, flags *flag.FlagSet, args []string) int {
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	switch flags.NArg() {
+	case 0:
+		c.usage(c.stdout)
+		return 0
+	case 1:
+		cmd := lookupCommand(flags.Arg(0))
+		if cmd == nil {
+			fmt.Fprintf(c.stderr, "kev help: unknown command %q\n", flags.Arg(0))
+			return 2
+		}
+		// running the command with -h shows its usage,
+		// which is written to stdout since it was asked for
+		helpCli := &cli{stdin: c.stdin, stdout: c.stdout, stderr: c.stdout}
+		cmd.run(helpCli, helpCli.flagSet(cmd), []string{"-h"})
+		return 0
+	default:
+		return usageError(flags, "kev help: too many arguments")
+	}
+}
+
+// isStdin returns whether the paths passed to a
+// command mean its source should be read from stdin
+func isStdin(paths []string) bool {
+	return len(paths) == 0 || (len(paths) == 1 && paths[0] == "-")
+}
+
+// kevFiles returns the files at the paths. Directories are searched
+// recursively for files ending in the suffix, skipping hidden directories,
+// while files passed directly are always included
+func kevFiles(paths []string, suffix string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), suffix) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// parseErrors returns the errors of parsing the source
+func parseErrors(src []byte) []string {
+	p := parser.New(lexer.New(string(src)))
+	p.ParseProgram()
+	return p.Errors()
+}
+
+// reportErrors writes each error on its own line, prefixed with the file
+func reportErrors(w io.Writer, file string, errs ...string) {
+	for _, err := range errs {
+		for _, line := range strings.Split(err, "\n") {
+			fmt.Fprintf(w, "%s: %s\n", file, line)
+		}
+	}
+}
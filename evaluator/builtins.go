@@ -1,85 +1,199 @@
 package evaluator
 
 import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/Gage-McGuire/kev/object"
 )
 
 // Builtins is a map of built-in functions
 var builtins = map[string]*object.Builtin{
 
+	// print function writes each object
+	// passed to it on its own line
 	"print": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			for _, arg := range args {
-				println(arg.Inspect())
+				io.WriteString(ctx.Stdout, arg.Inspect()+"\n")
+			}
+			return &object.String{Value: ""}
+		},
+	},
+
+	// println function writes the objects passed to it
+	// on a single line, separated by spaces
+	"println": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			values := make([]string, len(args))
+			for idx, arg := range args {
+				values[idx] = arg.Inspect()
+			}
+			io.WriteString(ctx.Stdout, strings.Join(values, " ")+"\n")
+			return &object.String{Value: ""}
+		},
+	},
+
+	// printf function formats the objects passed to it
+	// with the format string and writes the result
+	// without adding a line break
+	"printf": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+			}
+			format, ok := args[0].(*object.String)
+			if !ok {
+				return newError("first argument to `printf` must be STRING, got %s", args[0].Type())
 			}
+			io.WriteString(ctx.Stdout, formatObjects(format.Value, args[1:]))
 			return &object.String{Value: ""}
 		},
 	},
 
+	// eprint function works like println
+	// but writes to stderr instead of stdout
+	"eprint": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			values := make([]string, len(args))
+			for idx, arg := range args {
+				values[idx] = arg.Inspect()
+			}
+			io.WriteString(ctx.Stderr, strings.Join(values, " ")+"\n")
+			return &object.String{Value: ""}
+		},
+	},
+
+	// input function reads the next line from stdin,
+	// writing the optional prompt passed to it first.
+	// It returns null once there is nothing left to read
+	"input": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			if len(args) == 1 {
+				io.WriteString(ctx.Stdout, args[0].Inspect())
+			}
+			line, err := ctx.ReadLine()
+			if err == io.EOF {
+				return NULL
+			}
+			if err != nil {
+				return newError("could not read input: %s", err)
+			}
+			return &object.String{Value: line}
+		},
+	},
+
+	// assert function returns an error, which stops the program,
+	// when the condition passed to it isn't truthy. The optional
+	// message is added to the error
+	// example: assert(len(arr) > 0, "arr is empty")
+	"assert": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			if isTruthy(args[0]) {
+				return NULL
+			}
+			if len(args) == 2 {
+				return newError("assertion failed: %s", args[1].Inspect())
+			}
+			return newError("assertion failed")
+		},
+	},
+
+	// assertEqual function returns an error, which stops the
+	// program, when the two objects passed to it aren't equal.
+	// Objects are compared the same way == compares them
+	// example: assertEqual(sum([1, 2]), 3)
+	"assertEqual": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if object.Equal(args[0], args[1]) {
+				return NULL
+			}
+			return newError("assertion failed: expected %s, got %s", args[1].Inspect(), args[0].Inspect())
+		},
+	},
+
 	// len function returns the length of the object
-	// passed to it. It only supports strings... For now.
+	// passed to it. It supports strings, arrays, hashes
+	// and any object that implements object.Iterable.
+	// The length of a string is its number of characters
 	"len": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(arg.Len())}
 			default:
+				if elements, ok := iterate(arg); ok {
+					return &object.Integer{Value: int64(len(elements))}
+				}
 				return newError("argument to `len` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 
 	"first": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
-			if args[0].Type() != object.ARRAY_OBJ {
+			elements, ok := iterate(args[0])
+			if !ok {
 				return newError("argument to `first` must be ARRAY, got %s", args[0].Type())
 			}
-			arr := args[0].(*object.Array)
-			if len(arr.Elements) > 0 {
-				return arr.Elements[0]
+			if len(elements) > 0 {
+				return elements[0]
 			}
 			return NULL
 		},
 	},
 
 	"last": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
-			if args[0].Type() != object.ARRAY_OBJ {
+			elements, ok := iterate(args[0])
+			if !ok {
 				return newError("argument to `last` must be ARRAY, got %s", args[0].Type())
 			}
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
+			length := len(elements)
 			if length > 0 {
-				return arr.Elements[length-1]
+				return elements[length-1]
 			}
 			return NULL
 		},
 	},
 
 	"tail": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
-			if args[0].Type() != object.ARRAY_OBJ {
+			elements, ok := iterate(args[0])
+			if !ok {
 				return newError("argument to `tail` must be ARRAY, got %s", args[0].Type())
 			}
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
+			length := len(elements)
 			if length > 0 {
 				newElements := make([]object.Object, length-1)
-				copy(newElements, arr.Elements[1:length])
+				copy(newElements, elements[1:length])
 				return &object.Array{Elements: newElements}
 			}
 			return NULL
@@ -87,7 +201,7 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"push": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			if len(args) != 2 {
 				return newError("wrong number of arguments. got=%d, want=2", len(args))
 			}
@@ -102,4 +216,149 @@ var builtins = map[string]*object.Builtin{
 			return &object.Array{Elements: newElements}
 		},
 	},
+
+	// keys function returns an array
+	// holding the keys of the hash
+	"keys": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argument to `keys` must be HASH, got %s", args[0].Type())
+			}
+			hash := args[0].(*object.Hash)
+			keys := make([]object.Object, 0, hash.Len())
+			for _, pair := range hash.Pairs() {
+				keys = append(keys, pair.Key)
+			}
+			return &object.Array{Elements: keys}
+		},
+	},
+
+	// values function returns an array
+	// holding the values of the hash
+	"values": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argument to `values` must be HASH, got %s", args[0].Type())
+			}
+			hash := args[0].(*object.Hash)
+			values := make([]object.Object, 0, hash.Len())
+			for _, pair := range hash.Pairs() {
+				values = append(values, pair.Value)
+			}
+			return &object.Array{Elements: values}
+		},
+	},
+
+	// entries function returns an array holding
+	// a [key, value] array for every pair in the hash
+	"entries": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argument to `entries` must be HASH, got %s", args[0].Type())
+			}
+			hash := args[0].(*object.Hash)
+			entries := make([]object.Object, 0, hash.Len())
+			for _, pair := range hash.Pairs() {
+				entry := &object.Array{Elements: []object.Object{pair.Key, pair.Value}}
+				entries = append(entries, entry)
+			}
+			return &object.Array{Elements: entries}
+		},
+	},
+
+	// has function checks if the hash
+	// holds a pair for the given key
+	"has": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argument to `has` must be HASH, got %s", args[0].Type())
+			}
+			hash := args[0].(*object.Hash)
+			if _, ok := args[1].(object.Hashable); !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+			_, ok := hash.Get(args[1])
+			return nativeBoolToBooleanObject(ok)
+		},
+	},
+
+	// delete function returns a new hash holding every
+	// pair of the given hash except the one for the key.
+	// The given hash is left untouched
+	"delete": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argument to `delete` must be HASH, got %s", args[0].Type())
+			}
+			if _, ok := args[1].(object.Hashable); !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+			hash := args[0].(*object.Hash).Copy()
+			hash.Delete(args[1])
+			return hash
+		},
+	},
+
+	// merge function returns a new hash holding the pairs
+	// of every hash passed to it. When more than one hash
+	// holds the same key, the value of the last one wins
+	"merge": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+			}
+			hash := object.NewHash()
+			for _, arg := range args {
+				if arg.Type() != object.HASH_OBJ {
+					return newError("arguments to `merge` must be HASH, got %s", arg.Type())
+				}
+				for _, pair := range arg.(*object.Hash).Pairs() {
+					hash.Set(pair.Key, pair.Value)
+				}
+			}
+			return hash
+		},
+	},
+}
+
+// namespaces are built-in modules, like math, whose members
+// are reached with member access, e.g. math.sqrt(2). Like
+// builtins, they can be shadowed by bindings with the same name
+var namespaces = map[string]*object.Module{}
+
+// formatObjects formats the objects with a printf style format string.
+// Numbers, strings and booleans are handed to the verbs as their
+// native Go values, everything else is formatted as its Inspect() string
+func formatObjects(format string, args []object.Object) string {
+	values := make([]interface{}, len(args))
+	for idx, arg := range args {
+		switch arg := arg.(type) {
+		case *object.Integer:
+			values[idx] = arg.Value
+		case *object.Float:
+			values[idx] = arg.Value
+		case *object.String:
+			values[idx] = arg.Value
+		case *object.Boolean:
+			values[idx] = arg.Value
+		default:
+			values[idx] = arg.Inspect()
+		}
+	}
+	return fmt.Sprintf(format, values...)
 }
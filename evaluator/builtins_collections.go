@@ -0,0 +1,379 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// collectionBuiltins are the built-in functions that work on whole
+// collections. Many of them call back into kev functions, which means
+// they need applyFunction, so they live in their own map and are
+// added to builtins in init() to avoid an initialization cycle
+var collectionBuiltins = map[string]*object.Builtin{
+
+	// map function returns an array holding the result
+	// of calling the function with each element
+	// example: map([1, 2], func(x) { x * 2 }) -> [2, 4]
+	"map": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			elements, ok := iterate(args[0])
+			if !ok {
+				return newError("first argument to `map` must be ARRAY, got %s", args[0].Type())
+			}
+			mapped := make([]object.Object, len(elements))
+			for idx, el := range elements {
+				result := applyFunction(ctx, args[1], []object.Object{el})
+				if isError(result) {
+					return result
+				}
+				mapped[idx] = result
+			}
+			return &object.Array{Elements: mapped}
+		},
+	},
+
+	// filter function returns an array holding the elements
+	// the function returned a truthy value for
+	// example: filter([1, 2, 3], func(x) { x > 1 }) -> [2, 3]
+	"filter": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			elements, ok := iterate(args[0])
+			if !ok {
+				return newError("first argument to `filter` must be ARRAY, got %s", args[0].Type())
+			}
+			filtered := []object.Object{}
+			for _, el := range elements {
+				result := applyFunction(ctx, args[1], []object.Object{el})
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					filtered = append(filtered, el)
+				}
+			}
+			return &object.Array{Elements: filtered}
+		},
+	},
+
+	// reduce function combines the elements into a single value by
+	// calling the function with the value so far and each element.
+	// Without an initial value the first element is used instead
+	// example: reduce([1, 2, 3], func(acc, x) { acc + x }, 0) -> 6
+	"reduce": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+			elements, ok := iterate(args[0])
+			if !ok {
+				return newError("first argument to `reduce` must be ARRAY, got %s", args[0].Type())
+			}
+			var acc object.Object
+			if len(args) == 3 {
+				acc = args[2]
+			} else {
+				if len(elements) == 0 {
+					return NULL
+				}
+				acc = elements[0]
+				elements = elements[1:]
+			}
+			for _, el := range elements {
+				acc = applyFunction(ctx, args[1], []object.Object{acc, el})
+				if isError(acc) {
+					return acc
+				}
+			}
+			return acc
+		},
+	},
+
+	// sort function returns a sorted copy of the array. Without a
+	// comparator the elements are ordered with <. A comparator is
+	// called with two elements and returns true, or a negative
+	// integer, when the first one belongs before the second one
+	// example: sort([3, 1, 2]) -> [1, 2, 3]
+	// example: sort([1, 2, 3], func(a, b) { a > b }) -> [3, 2, 1]
+	"sort": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			elements, ok := iterate(args[0])
+			if !ok {
+				return newError("first argument to `sort` must be ARRAY, got %s", args[0].Type())
+			}
+			sorted := make([]object.Object, len(elements))
+			copy(sorted, elements)
+
+			// the first error stops the comparisons from
+			// mattering and is returned once sorting is done
+			var sortErr object.Object
+			less := func(a, b object.Object) bool {
+				if sortErr != nil {
+					return false
+				}
+				if len(args) == 1 {
+					cmp, ok := object.Compare(a, b)
+					if !ok {
+						sortErr = newError("cannot compare: %s < %s", a.Inspect(), b.Inspect())
+						return false
+					}
+					return cmp < 0
+				}
+				result := applyFunction(ctx, args[1], []object.Object{a, b})
+				switch result := result.(type) {
+				case *object.Error:
+					sortErr = result
+					return false
+				case *object.Boolean:
+					return result.Value
+				case *object.Integer:
+					return result.Value < 0
+				default:
+					sortErr = newError("comparator for `sort` must return BOOLEAN or INTEGER, got %s", result.Type())
+					return false
+				}
+			}
+			sort.SliceStable(sorted, func(i, j int) bool {
+				return less(sorted[i], sorted[j])
+			})
+			if sortErr != nil {
+				return sortErr
+			}
+			return &object.Array{Elements: sorted}
+		},
+	},
+
+	// find function returns the first element the function
+	// returned a truthy value for, or null if there isn't one
+	// example: find([1, 2, 3], func(x) { x > 1 }) -> 2
+	"find": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			elements, ok := iterate(args[0])
+			if !ok {
+				return newError("first argument to `find` must be ARRAY, got %s", args[0].Type())
+			}
+			for _, el := range elements {
+				result := applyFunction(ctx, args[1], []object.Object{el})
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return el
+				}
+			}
+			return NULL
+		},
+	},
+
+	// any function checks if at least one element is truthy, or if
+	// the function returned a truthy value for at least one element
+	// example: any([1, 2, 3], func(x) { x > 2 }) -> true
+	"any": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			return matchElements(ctx, "any", args, true)
+		},
+	},
+
+	// all function checks if every element is truthy, or if
+	// the function returned a truthy value for every element
+	// example: all([1, 2, 3], func(x) { x > 2 }) -> false
+	"all": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			return matchElements(ctx, "all", args, false)
+		},
+	},
+
+	// zip function returns an array pairing up the elements
+	// at the same index of every array passed to it. It's as
+	// long as the shortest array
+	// example: zip([1, 2], ["a", "b"]) -> [[1, a], [2, b]]
+	"zip": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+			}
+			arrays := make([][]object.Object, len(args))
+			length := -1
+			for idx, arg := range args {
+				elements, ok := iterate(arg)
+				if !ok {
+					return newError("arguments to `zip` must be ARRAY, got %s", arg.Type())
+				}
+				arrays[idx] = elements
+				if length == -1 || len(elements) < length {
+					length = len(elements)
+				}
+			}
+			zipped := make([]object.Object, length)
+			for idx := range zipped {
+				group := make([]object.Object, len(arrays))
+				for arrayIdx, elements := range arrays {
+					group[arrayIdx] = elements[idx]
+				}
+				zipped[idx] = &object.Array{Elements: group}
+			}
+			return &object.Array{Elements: zipped}
+		},
+	},
+
+	// enumerate function returns an array pairing
+	// up each element with its index
+	// example: enumerate(["a", "b"]) -> [[0, a], [1, b]]
+	"enumerate": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			elements, ok := iterate(args[0])
+			if !ok {
+				return newError("argument to `enumerate` must be ARRAY, got %s", args[0].Type())
+			}
+			enumerated := make([]object.Object, len(elements))
+			for idx, el := range elements {
+				index := &object.Integer{Value: int64(idx)}
+				enumerated[idx] = &object.Array{Elements: []object.Object{index, el}}
+			}
+			return &object.Array{Elements: enumerated}
+		},
+	},
+
+	// flatten function returns an array with the nested arrays
+	// replaced by their elements. Without a depth every level
+	// is flattened, otherwise only depth levels are
+	// example: flatten([1, [2, [3]]]) -> [1, 2, 3]
+	// example: flatten([1, [2, [3]]], 1) -> [1, 2, [3]]
+	"flatten": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			if args[0].Type() != object.ARRAY_OBJ {
+				return newError("first argument to `flatten` must be ARRAY, got %s", args[0].Type())
+			}
+			depth := int64(-1)
+			if len(args) == 2 {
+				integer, ok := args[1].(*object.Integer)
+				if !ok {
+					return newError("second argument to `flatten` must be INTEGER, got %s", args[1].Type())
+				}
+				depth = integer.Value
+			}
+			return &object.Array{Elements: flattenElements(args[0].(*object.Array).Elements, depth)}
+		},
+	},
+
+	// range function returns an array of integers from start up
+	// to, but not including, end. start defaults to 0 and step to 1.
+	// A negative step counts down from start to end
+	// example: range(3) -> [0, 1, 2]
+	// example: range(1, 10, 3) -> [1, 4, 7]
+	"range": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1, 2 or 3", len(args))
+			}
+			values := make([]int64, len(args))
+			for idx, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("arguments to `range` must be INTEGER, got %s", arg.Type())
+				}
+				values[idx] = integer.Value
+			}
+			start, end, step := int64(0), values[0], int64(1)
+			if len(values) > 1 {
+				start, end = values[0], values[1]
+			}
+			if len(values) > 2 {
+				step = values[2]
+			}
+			if step == 0 {
+				return newError("step of `range` must not be 0")
+			}
+			count := rangeLength(start, end, step)
+			if count > maxRangeLength {
+				return newError("`range` is too long, the limit is %d elements", maxRangeLength)
+			}
+			elements := make([]object.Object, count)
+			for idx := range elements {
+				elements[idx] = &object.Integer{Value: start + int64(idx)*step}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range collectionBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// maxRangeLength is the most elements range builds,
+// so a script can't exhaust memory
+const maxRangeLength = 1 << 24
+
+// rangeLength returns how many elements range builds from start to
+// end. The distance is taken as unsigned so it can't overflow, even
+// when start and end are at opposite ends of the integers
+func rangeLength(start, end, step int64) uint64 {
+	if step > 0 && start < end {
+		return (uint64(end)-uint64(start)-1)/uint64(step) + 1
+	}
+	if step < 0 && start > end {
+		return (uint64(start)-uint64(end)-1)/(0-uint64(step)) + 1
+	}
+	return 0
+}
+
+// matchElements is shared by any and all. It stops at the first
+// element whose truthiness equals stopOn and returns stopOn,
+// otherwise it returns the opposite once every element is checked
+func matchElements(ctx *object.Context, name string, args []object.Object, stopOn bool) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	elements, ok := iterate(args[0])
+	if !ok {
+		return newError("first argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	for _, el := range elements {
+		result := el
+		if len(args) == 2 {
+			result = applyFunction(ctx, args[1], []object.Object{el})
+			if isError(result) {
+				return result
+			}
+		}
+		if isTruthy(result) == stopOn {
+			return nativeBoolToBooleanObject(stopOn)
+		}
+	}
+	return nativeBoolToBooleanObject(!stopOn)
+}
+
+// flattenElements replaces nested arrays with their elements,
+// going depth levels down. A negative depth has no limit
+func flattenElements(elements []object.Object, depth int64) []object.Object {
+	flattened := []object.Object{}
+	for _, el := range elements {
+		if array, ok := el.(*object.Array); ok && depth != 0 {
+			flattened = append(flattened, flattenElements(array.Elements, depth-1)...)
+			continue
+		}
+		flattened = append(flattened, el)
+	}
+	return flattened
+}
@@ -0,0 +1,151 @@
+package evaluator
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// fileBuiltins work on the files of the context's file system,
+// which the program running kev controls. Relative paths are resolved
+// by the file system, which for the operating system's files means
+// against the working directory
+var fileBuiltins = map[string]*object.Builtin{
+
+	// readFile function returns the contents of the file as a string
+	// example: readFile("config.json")
+	"readFile": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, errObj := stringArgs("readFile", args, 1)
+			if errObj != nil {
+				return errObj
+			}
+			data, err := ctx.FileSystem().ReadFile(strs[0])
+			if err != nil {
+				return newError("could not read file: %s", err)
+			}
+			return &object.String{Value: string(data)}
+		},
+	},
+
+	// writeFile function writes the string to the file,
+	// creating the file or replacing what was in it
+	// example: writeFile("out.txt", "hello")
+	"writeFile": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, errObj := stringArgs("writeFile", args, 2)
+			if errObj != nil {
+				return errObj
+			}
+			if err := ctx.FileSystem().WriteFile(strs[0], []byte(strs[1])); err != nil {
+				return newError("could not write file: %s", err)
+			}
+			return NULL
+		},
+	},
+
+	// appendFile function adds the string to the end of
+	// the file, creating the file when it doesn't exist
+	// example: appendFile("log.txt", "started")
+	"appendFile": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, errObj := stringArgs("appendFile", args, 2)
+			if errObj != nil {
+				return errObj
+			}
+			if err := ctx.FileSystem().AppendFile(strs[0], []byte(strs[1])); err != nil {
+				return newError("could not append to file: %s", err)
+			}
+			return NULL
+		},
+	},
+
+	// listDir function returns the sorted names of the files
+	// and directories in the directory, which defaults to
+	// the working directory
+	// example: listDir("src")
+	"listDir": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			dir := "."
+			if len(args) > 0 {
+				strs, errObj := stringArgs("listDir", args, 1)
+				if errObj != nil {
+					return errObj
+				}
+				dir = strs[0]
+			}
+			entries, err := ctx.FileSystem().ReadDir(dir)
+			if err != nil {
+				return newError("could not list directory: %s", err)
+			}
+			elements := make([]object.Object, len(entries))
+			for idx, entry := range entries {
+				elements[idx] = &object.String{Value: entry.Name()}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	// exists function returns whether there
+	// is a file or directory at the path
+	// example: exists("config.json")
+	"exists": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, errObj := stringArgs("exists", args, 1)
+			if errObj != nil {
+				return errObj
+			}
+			_, err := ctx.FileSystem().Stat(strs[0])
+			if errors.Is(err, fs.ErrNotExist) {
+				return FALSE
+			}
+			if err != nil {
+				return newError("could not check file: %s", err)
+			}
+			return TRUE
+		},
+	},
+
+	// isDir function returns whether
+	// there is a directory at the path
+	// example: isDir("src")
+	"isDir": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, errObj := stringArgs("isDir", args, 1)
+			if errObj != nil {
+				return errObj
+			}
+			info, err := ctx.FileSystem().Stat(strs[0])
+			if errors.Is(err, fs.ErrNotExist) {
+				return FALSE
+			}
+			if err != nil {
+				return newError("could not check file: %s", err)
+			}
+			return nativeBoolToBooleanObject(info.IsDir())
+		},
+	},
+
+	// remove function removes the file
+	// or the empty directory at the path
+	// example: remove("out.txt")
+	"remove": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, errObj := stringArgs("remove", args, 1)
+			if errObj != nil {
+				return errObj
+			}
+			if err := ctx.FileSystem().Remove(strs[0]); err != nil {
+				return newError("could not remove file: %s", err)
+			}
+			return NULL
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range fileBuiltins {
+		builtins[name] = builtin
+	}
+}
@@ -0,0 +1,227 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// maxJSONIndent is the most spaces stringify indents with
+const maxJSONIndent = 10
+
+// jsonNamespace holds the members of the json namespace,
+// reached with member access, e.g. json.parse(src)
+var jsonNamespace = map[string]object.Object{
+
+	// parse function turns the JSON string passed to it into kev
+	// objects. Objects become hashes that keep the order of their
+	// keys, arrays become arrays, whole numbers become integers,
+	// other numbers become floats and null becomes null
+	// example: json.parse("[1, 2.5, null]") -> [1, 2.5, null]
+	"parse": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("parse", args, 1)
+			if err != nil {
+				return err
+			}
+			obj, parseErr := parseJSON(strs[0])
+			if parseErr != nil {
+				return newError("could not parse JSON: %s", parseErr)
+			}
+			return obj
+		},
+	},
+
+	// stringify function turns the object passed to it into a
+	// JSON string. The optional indent, either a number of spaces
+	// or a string, pretty-prints it. A number of spaces can be at
+	// most maxJSONIndent. Only hashes with string keys
+	// can be turned into JSON, and functions can't be at all
+	// example: json.stringify({"a": [1, 2]}) -> {"a":[1,2]}
+	"stringify": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			indent := ""
+			if len(args) == 2 {
+				switch arg := args[1].(type) {
+				case *object.Integer:
+					if arg.Value < 0 {
+						return newError("indent for `stringify` must not be negative, got %d", arg.Value)
+					}
+					if arg.Value > maxJSONIndent {
+						return newError("indent for `stringify` must be at most %d, got %d", maxJSONIndent, arg.Value)
+					}
+					indent = strings.Repeat(" ", int(arg.Value))
+				case *object.String:
+					indent = arg.Value
+				default:
+					return newError("second argument to `stringify` must be INTEGER or STRING, got %s", args[1].Type())
+				}
+			}
+
+			var out bytes.Buffer
+			if err := writeJSON(&out, args[0]); err != nil {
+				return newError("could not stringify: %s", err)
+			}
+			if indent != "" {
+				var pretty bytes.Buffer
+				if err := json.Indent(&pretty, out.Bytes(), "", indent); err != nil {
+					return newError("could not stringify: %s", err)
+				}
+				return &object.String{Value: pretty.String()}
+			}
+			return &object.String{Value: out.String()}
+		},
+	},
+}
+
+func init() {
+	namespaces["json"] = &object.Module{Name: "json", Exports: jsonNamespace}
+}
+
+// parseJSON decodes the JSON source into kev objects.
+// The source must hold exactly one JSON value
+func parseJSON(src string) (object.Object, error) {
+	dec := json.NewDecoder(strings.NewReader(src))
+	dec.UseNumber()
+
+	obj, err := decodeJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return obj, nil
+}
+
+// decodeJSON decodes the next JSON value from the decoder. It reads
+// token by token, instead of decoding into Go maps, so the keys of
+// the resulting hashes stay in the order they were written
+func decodeJSON(dec *json.Decoder) (object.Object, error) {
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			elements := []object.Object{}
+			for dec.More() {
+				el, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, el)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return &object.Array{Elements: elements}, nil
+		}
+
+		hash := object.NewHash()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(&object.String{Value: key.(string)}, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return hash, nil
+	case json.Number:
+		if integer, err := tok.Int64(); err == nil {
+			return &object.Integer{Value: integer}, nil
+		}
+		float, err := tok.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return &object.Float{Value: float}, nil
+	case string:
+		return &object.String{Value: tok}, nil
+	case bool:
+		return nativeBoolToBooleanObject(tok), nil
+	default:
+		return NULL, nil
+	}
+}
+
+// writeJSON writes the object to out as compact JSON.
+// Hashes are written in the order of their pairs
+func writeJSON(out *bytes.Buffer, obj object.Object) error {
+	switch obj := obj.(type) {
+	case *object.Null:
+		out.WriteString("null")
+	case *object.Boolean:
+		out.WriteString(obj.Inspect())
+	case *object.Integer:
+		out.WriteString(obj.Inspect())
+	case *object.Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return fmt.Errorf("%s can't be represented in JSON", obj.Inspect())
+		}
+		out.WriteString(obj.Inspect())
+	case *object.String:
+		writeJSONString(out, obj.Value)
+	case *object.Array:
+		out.WriteByte('[')
+		for idx, el := range obj.Elements {
+			if idx > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeJSON(out, el); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	case *object.Hash:
+		out.WriteByte('{')
+		for idx, pair := range obj.Pairs() {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return fmt.Errorf("hash key %s is %s, JSON keys must be STRING", pair.Key.Inspect(), pair.Key.Type())
+			}
+			if idx > 0 {
+				out.WriteByte(',')
+			}
+			writeJSONString(out, key.Value)
+			out.WriteByte(':')
+			if err := writeJSON(out, pair.Value); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	default:
+		return fmt.Errorf("%s can't be represented in JSON", obj.Type())
+	}
+	return nil
+}
+
+// writeJSONString writes the string to out as a quoted JSON string
+func writeJSONString(out *bytes.Buffer, str string) {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(str)
+	// Encode ends every value with a line break
+	out.Truncate(out.Len() - 1)
+}
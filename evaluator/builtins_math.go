@@ -0,0 +1,330 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// mathNamespace holds the members of the math namespace,
+// reached with member access, e.g. math.sqrt(2) or math.PI.
+//
+// Functions accept integers and floats. abs, min, max and
+// clamp return an integer when every number passed to them is
+// an integer and a float otherwise. pow returns an integer for
+// an integer raised to a non-negative integer and a float
+// otherwise. floor, ceil and round return integers. sqrt,
+// the trig functions, log and exp always return floats. gcd
+// only works on integers. An integer result that doesn't fit
+// in an integer is an error rather than wrapping around
+var mathNamespace = map[string]object.Object{
+	"PI": &object.Float{Value: math.Pi},
+	"E":  &object.Float{Value: math.E},
+
+	// abs function returns the absolute value of the number
+	// example: math.abs(-2) -> 2
+	"abs": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := numberArgs("abs", args, 1); err != nil {
+				return err
+			}
+			if integer, ok := args[0].(*object.Integer); ok {
+				if integer.Value == math.MinInt64 {
+					return newError("result of `abs` overflows INTEGER")
+				}
+				if integer.Value < 0 {
+					return &object.Integer{Value: -integer.Value}
+				}
+				return integer
+			}
+			return floatResult(math.Abs, args[0])
+		},
+	},
+
+	// min function returns the smallest of the numbers passed
+	// to it, or of the numbers in the array passed to it
+	// example: math.min(3, 1, 2) -> 1
+	// example: math.min([2.5, 4]) -> 2.5
+	"min": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			return extremum("min", args, -1)
+		},
+	},
+
+	// max function returns the largest of the numbers passed
+	// to it, or of the numbers in the array passed to it
+	// example: math.max(3, 1, 2) -> 3
+	"max": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			return extremum("max", args, 1)
+		},
+	},
+
+	// clamp function limits the number to the range low to high
+	// example: math.clamp(15, 0, 10) -> 10
+	"clamp": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := numberArgs("clamp", args, 3); err != nil {
+				return err
+			}
+			value, low, high := args[0], args[1], args[2]
+			if cmp, _ := object.Compare(low, high); cmp > 0 {
+				return newError("low bound of `clamp` is greater than its high bound")
+			}
+			if cmp, _ := object.Compare(value, low); cmp < 0 {
+				value = low
+			}
+			if cmp, _ := object.Compare(value, high); cmp > 0 {
+				value = high
+			}
+			return promote(value, args...)
+		},
+	},
+
+	// pow function raises the base to the exponent
+	// example: math.pow(2, 10) -> 1024
+	// example: math.pow(2, -1) -> 0.5
+	"pow": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := numberArgs("pow", args, 2); err != nil {
+				return err
+			}
+			base, baseIsInt := args[0].(*object.Integer)
+			exp, expIsInt := args[1].(*object.Integer)
+			if baseIsInt && expIsInt && exp.Value >= 0 {
+				// exponentiation by squaring, where the base is
+				// only squared again while it's still needed
+				result, b, e := int64(1), base.Value, exp.Value
+				for ok := true; e > 0; e >>= 1 {
+					if e&1 == 1 {
+						if result, ok = multiplyInts(result, b); !ok {
+							return newError("result of `pow` overflows INTEGER")
+						}
+					}
+					if e > 1 {
+						if b, ok = multiplyInts(b, b); !ok {
+							return newError("result of `pow` overflows INTEGER")
+						}
+					}
+				}
+				return &object.Integer{Value: result}
+			}
+			x, _ := object.ToFloat(args[0])
+			y, _ := object.ToFloat(args[1])
+			return &object.Float{Value: math.Pow(x, y)}
+		},
+	},
+
+	// sqrt function returns the square root of the number
+	// example: math.sqrt(16) -> 4.0
+	"sqrt": floatFunction("sqrt", math.Sqrt),
+
+	// floor function rounds the number down to an integer
+	// example: math.floor(2.7) -> 2
+	"floor": roundingFunction("floor", math.Floor),
+
+	// ceil function rounds the number up to an integer
+	// example: math.ceil(2.1) -> 3
+	"ceil": roundingFunction("ceil", math.Ceil),
+
+	// round function rounds the number to the nearest integer,
+	// rounding halves away from zero. With the optional number
+	// of digits it rounds to that many decimal places instead
+	// and returns a float
+	// example: math.round(2.5) -> 3
+	// example: math.round(3.14159, 2) -> 3.14
+	"round": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) == 2 {
+				if err := numberArgs("round", args[:1], 1); err != nil {
+					return err
+				}
+				digits, ok := args[1].(*object.Integer)
+				if !ok {
+					return newError("second argument to `round` must be INTEGER, got %s", args[1].Type())
+				}
+				x, _ := object.ToFloat(args[0])
+				scale := math.Pow(10, float64(digits.Value))
+				switch {
+				case scale == 0:
+					// rounding to more digits before the point than
+					// any float has always ends up at zero
+					return &object.Float{Value: math.Copysign(0, x)}
+				case math.IsInf(x*scale, 0):
+					// the float has no digits that far after the
+					// point, so there is nothing to round
+					return &object.Float{Value: x}
+				}
+				return &object.Float{Value: math.Round(x*scale) / scale}
+			}
+			return roundingFunction("round", math.Round).Func(ctx, args...)
+		},
+	},
+
+	// trig functions work with angles in radians
+	"sin":  floatFunction("sin", math.Sin),
+	"cos":  floatFunction("cos", math.Cos),
+	"tan":  floatFunction("tan", math.Tan),
+	"asin": floatFunction("asin", math.Asin),
+	"acos": floatFunction("acos", math.Acos),
+	"atan": floatFunction("atan", math.Atan),
+
+	// atan2 function returns the angle of the point y, x
+	// example: math.atan2(1, 1) -> 0.7853981633974483
+	"atan2": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := numberArgs("atan2", args, 2); err != nil {
+				return err
+			}
+			y, _ := object.ToFloat(args[0])
+			x, _ := object.ToFloat(args[1])
+			return &object.Float{Value: math.Atan2(y, x)}
+		},
+	},
+
+	// log function returns the natural logarithm of the number
+	"log":   floatFunction("log", math.Log),
+	"log2":  floatFunction("log2", math.Log2),
+	"log10": floatFunction("log10", math.Log10),
+
+	// exp function returns E raised to the number
+	"exp": floatFunction("exp", math.Exp),
+
+	// gcd function returns the greatest common divisor of the integers
+	// example: math.gcd(12, 18) -> 6
+	"gcd": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			a, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("arguments to `gcd` must be INTEGER, got %s", args[0].Type())
+			}
+			b, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("arguments to `gcd` must be INTEGER, got %s", args[1].Type())
+			}
+			x, y := a.Value, b.Value
+			for y != 0 {
+				x, y = y, x%y
+			}
+			if x == math.MinInt64 {
+				return newError("result of `gcd` overflows INTEGER")
+			}
+			if x < 0 {
+				x = -x
+			}
+			return &object.Integer{Value: x}
+		},
+	},
+}
+
+func init() {
+	namespaces["math"] = &object.Module{Name: "math", Exports: mathNamespace}
+}
+
+// numberArgs checks that the builtin was called
+// with want arguments that are all numbers
+func numberArgs(name string, args []object.Object, want int) *object.Error {
+	if len(args) != want {
+		return newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+	for _, arg := range args {
+		if !isNumber(arg) {
+			return newError("arguments to `%s` must be INTEGER or FLOAT, got %s", name, arg.Type())
+		}
+	}
+	return nil
+}
+
+// floatFunction returns a builtin that calls fn with
+// the number passed to it and always returns a float
+func floatFunction(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := numberArgs(name, args, 1); err != nil {
+				return err
+			}
+			return floatResult(fn, args[0])
+		},
+	}
+}
+
+// roundingFunction returns a builtin that rounds the number
+// passed to it with fn and returns the result as an integer
+func roundingFunction(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := numberArgs(name, args, 1); err != nil {
+				return err
+			}
+			if integer, ok := args[0].(*object.Integer); ok {
+				return integer
+			}
+			x := fn(args[0].(*object.Float).Value)
+			if math.IsNaN(x) || math.IsInf(x, 0) || x >= math.MaxInt64 || x < math.MinInt64 {
+				return newError("cannot convert %s to INTEGER", (&object.Float{Value: x}).Inspect())
+			}
+			return &object.Integer{Value: int64(x)}
+		},
+	}
+}
+
+// floatResult calls fn with the number and returns the result as a float
+func floatResult(fn func(float64) float64, number object.Object) object.Object {
+	x, _ := object.ToFloat(number)
+	return &object.Float{Value: fn(x)}
+}
+
+// promote returns the number as a float when any
+// of the numbers it was picked from is a float
+func promote(number object.Object, from ...object.Object) object.Object {
+	for _, obj := range from {
+		if obj.Type() == object.FLOAT_OBJ {
+			x, _ := object.ToFloat(number)
+			return &object.Float{Value: x}
+		}
+	}
+	return number
+}
+
+// extremum is shared by min and max. It returns the number that
+// compares as sign against every other one, e.g. -1 for the smallest
+func extremum(name string, args []object.Object, sign int) object.Object {
+	numbers := args
+	if len(args) == 1 {
+		elements, ok := iterate(args[0])
+		if !ok {
+			return newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+		}
+		numbers = elements
+	}
+	if len(numbers) == 0 {
+		return newError("`%s` needs at least one number", name)
+	}
+	if err := numberArgs(name, numbers, len(numbers)); err != nil {
+		return err
+	}
+
+	result := numbers[0]
+	for _, number := range numbers[1:] {
+		if cmp, _ := object.Compare(number, result); cmp == sign {
+			result = number
+		}
+	}
+	return promote(result, numbers...)
+}
+
+// multiplyInts returns the product of the integers
+// and false when it doesn't fit in an integer
+func multiplyInts(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
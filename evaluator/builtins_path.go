@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"path/filepath"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// pathNamespace holds the members of the path namespace,
+// reached with member access, e.g. path.join("a", "b").
+// The functions only work on the path strings and never
+// look at the file system
+var pathNamespace = map[string]object.Object{
+
+	// join function joins the paths passed to it
+	// with the separator and cleans the result
+	// example: path.join("a", "b", "../c") -> a/c
+	"join": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("join", args, len(args))
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: filepath.Join(strs...)}
+		},
+	},
+
+	// base function returns the last element of the path
+	// example: path.base("src/main.kev") -> main.kev
+	"base": pathFunction("base", filepath.Base),
+
+	// dir function returns every element of the path but the last
+	// example: path.dir("src/main.kev") -> src
+	"dir": pathFunction("dir", filepath.Dir),
+
+	// ext function returns the extension of the path,
+	// including the dot, or an empty string when it has none
+	// example: path.ext("src/main.kev") -> .kev
+	"ext": pathFunction("ext", filepath.Ext),
+
+	// clean function returns the shortest path
+	// that names the same file as the path
+	// example: path.clean("a/./b/../c") -> a/c
+	"clean": pathFunction("clean", filepath.Clean),
+
+	// isAbs function returns whether the path is absolute
+	// example: path.isAbs("/tmp") -> true
+	"isAbs": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("isAbs", args, 1)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(filepath.IsAbs(strs[0]))
+		},
+	},
+}
+
+func init() {
+	namespaces["path"] = &object.Module{Name: "path", Exports: pathNamespace}
+}
+
+// pathFunction returns a builtin that calls
+// fn with the path string passed to it
+func pathFunction(name string, fn func(string) string) *object.Builtin {
+	return &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs(name, args, 1)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: fn(strs[0])}
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// processBuiltins are the built-in functions that work
+// with the process running the script, through the context
+var processBuiltins = map[string]*object.Builtin{
+
+	// env function returns the value of the environment
+	// variable, or the default passed to it when the variable
+	// isn't set, which is null when there is no default
+	// example: env("HOME")
+	// example: env("PORT", "8080")
+	"env": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			name, ok := args[0].(*object.String)
+			if !ok {
+				return newError("first argument to `env` must be STRING, got %s", args[0].Type())
+			}
+			if value, ok := ctx.Getenv(name.Value); ok {
+				return &object.String{Value: value}
+			}
+			if len(args) == 2 {
+				return args[1]
+			}
+			return NULL
+		},
+	},
+
+	// exit function stops the program, which exits with the
+	// status passed to it, or with 0 when none is passed.
+	// The status must be between 0 and 255
+	// example: exit(1)
+	"exit": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			code := 0
+			if len(args) == 1 {
+				integer, ok := args[0].(*object.Integer)
+				if !ok {
+					return newError("argument to `exit` must be INTEGER, got %s", args[0].Type())
+				}
+				if integer.Value < 0 || integer.Value > 255 {
+					return newError("status for `exit` must be between 0 and 255, got %d", integer.Value)
+				}
+				code = int(integer.Value)
+			}
+			return &object.Error{Message: fmt.Sprintf("exit status %d", code), Exit: true, Code: code}
+		},
+	},
+}
+
+// contextValues are names bound to values that come from the
+// context, like the arguments passed to the script. They are
+// looked up after the environment and the builtins, so a
+// binding with the same name hides them
+var contextValues = map[string]func(ctx *object.Context) object.Object{
+
+	// args holds the arguments passed to the script as strings
+	// example: kev run script.kev a b -> args is [a, b]
+	"args": func(ctx *object.Context) object.Object {
+		elements := make([]object.Object, len(ctx.Args))
+		for idx, arg := range ctx.Args {
+			elements[idx] = &object.String{Value: arg}
+		}
+		return &object.Array{Elements: elements}
+	},
+}
+
+func init() {
+	for name, builtin := range processBuiltins {
+		builtins[name] = builtin
+	}
+}
@@ -0,0 +1,307 @@
+package evaluator
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// reNamespace holds the members of the re namespace,
+// reached with member access, e.g. re.match("[0-9]+", s).
+// It uses Go's regexp syntax.
+//
+// Every function takes either a regex returned by re.compile
+// or a pattern string, which is compiled and cached, so calling
+// a function with the same pattern again doesn't compile it again.
+// Matches are arrays holding the whole match followed by each
+// capture group, with null for groups that didn't take part in it.
+// Like replace, it calls back into kev functions, so it is filled
+// in by init() to avoid an initialization cycle
+var reNamespace map[string]object.Object
+
+// regexCacheSize is the number of compiled pattern strings
+// kept around. The cache is emptied once it is full
+const regexCacheSize = 256
+
+// regexCache holds the regexes compiled from pattern strings
+var regexCache = struct {
+	sync.Mutex
+	regexes map[string]*object.Regex
+}{regexes: make(map[string]*object.Regex)}
+
+func init() {
+	reNamespace = map[string]object.Object{
+
+		// compile function compiles the pattern into a regex
+		// example: var digits = re.compile("[0-9]+")
+		"compile": &object.Builtin{
+			Func: func(ctx *object.Context, args ...object.Object) object.Object {
+				strs, err := stringArgs("compile", args, 1)
+				if err != nil {
+					return err
+				}
+				return compileRegex(strs[0])
+			},
+		},
+
+		// match function returns whether the
+		// regex matches anywhere in the string
+		// example: re.match("^[a-z]+$", "kev") -> true
+		"match": &object.Builtin{
+			Func: func(ctx *object.Context, args ...object.Object) object.Object {
+				regex, s, err := regexArgs("match", args, 2)
+				if err != nil {
+					return err
+				}
+				return nativeBoolToBooleanObject(regex.Regexp.MatchString(s))
+			},
+		},
+
+		// find function returns the first match of the
+		// regex in the string, or null when there is none
+		// example: re.find("([a-z]+)=([0-9]+)", "a=1 b=2") -> [a=1, a, 1]
+		"find": &object.Builtin{
+			Func: func(ctx *object.Context, args ...object.Object) object.Object {
+				regex, s, err := regexArgs("find", args, 2)
+				if err != nil {
+					return err
+				}
+				loc := regex.Regexp.FindStringSubmatchIndex(s)
+				if loc == nil {
+					return NULL
+				}
+				return &object.Array{Elements: matchGroups(s, loc)}
+			},
+		},
+
+		// findAll function returns every match of the regex
+		// in the string, or the first n when n is passed
+		// example: re.findAll("[0-9]", "a1b2") -> [[1], [2]]
+		"findAll": &object.Builtin{
+			Func: func(ctx *object.Context, args ...object.Object) object.Object {
+				regex, s, err := regexArgs("findAll", args, 2, 3)
+				if err != nil {
+					return err
+				}
+				n, err := regexLimit("findAll", args)
+				if err != nil {
+					return err
+				}
+				locs := regex.Regexp.FindAllStringSubmatchIndex(s, n)
+				matches := make([]object.Object, len(locs))
+				for idx, loc := range locs {
+					matches[idx] = &object.Array{Elements: matchGroups(s, loc)}
+				}
+				return &object.Array{Elements: matches}
+			},
+		},
+
+		// groups function returns a hash of the named capture groups
+		// of the first match, or null when the regex doesn't match
+		// example: re.groups("(?P<key>[a-z]+)=(?P<value>[0-9]+)", "a=1") -> {key: a, value: 1}
+		"groups": &object.Builtin{
+			Func: func(ctx *object.Context, args ...object.Object) object.Object {
+				regex, s, err := regexArgs("groups", args, 2)
+				if err != nil {
+					return err
+				}
+				loc := regex.Regexp.FindStringSubmatchIndex(s)
+				if loc == nil {
+					return NULL
+				}
+				groups := matchGroups(s, loc)
+				hash := object.NewHash()
+				for idx, name := range regex.Regexp.SubexpNames() {
+					if name != "" {
+						hash.Set(&object.String{Value: name}, groups[idx])
+					}
+				}
+				return hash
+			},
+		},
+
+		// replace function replaces every match of the regex in the
+		// string. The replacement is either a string, where $1 or
+		// ${name} stand for capture groups, or a function called with
+		// the whole match followed by each capture group, whose result
+		// replaces the match
+		// example: re.replace("[0-9]+", "a1b22", "#") -> a#b#
+		// example: re.replace("[0-9]+", "a1b22", func(m) { len(m) }) -> a1b2
+		"replace": &object.Builtin{
+			Func: func(ctx *object.Context, args ...object.Object) object.Object {
+				regex, s, err := regexArgs("replace", args, 3)
+				if err != nil {
+					return err
+				}
+				switch replacement := args[2].(type) {
+				case *object.String:
+					return &object.String{Value: regex.Regexp.ReplaceAllString(s, replacement.Value)}
+				case *object.Function, *object.Builtin, object.Callable:
+					return replaceWithFunction(ctx, regex, s, replacement)
+				default:
+					return newError("third argument to `replace` must be STRING or FUNCTION, got %s", args[2].Type())
+				}
+			},
+		},
+
+		// split function splits the string around every match of the
+		// regex, returning at most n parts when n is passed
+		// example: re.split(" *, *", "a , b,c") -> [a, b, c]
+		"split": &object.Builtin{
+			Func: func(ctx *object.Context, args ...object.Object) object.Object {
+				regex, s, err := regexArgs("split", args, 2, 3)
+				if err != nil {
+					return err
+				}
+				n, err := regexLimit("split", args)
+				if err != nil {
+					return err
+				}
+				parts := regex.Regexp.Split(s, n)
+				elements := make([]object.Object, len(parts))
+				for idx, part := range parts {
+					elements[idx] = &object.String{Value: part}
+				}
+				return &object.Array{Elements: elements}
+			},
+		},
+
+		// escape function returns a pattern
+		// that matches the string literally
+		// example: re.escape("1.5") -> 1\.5
+		"escape": &object.Builtin{
+			Func: func(ctx *object.Context, args ...object.Object) object.Object {
+				strs, err := stringArgs("escape", args, 1)
+				if err != nil {
+					return err
+				}
+				return &object.String{Value: regexp.QuoteMeta(strs[0])}
+			},
+		},
+	}
+
+	namespaces["re"] = &object.Module{Name: "re", Exports: reNamespace}
+}
+
+// compileRegex returns the regex compiled from the
+// pattern, reusing the cached one when there is one
+func compileRegex(pattern string) object.Object {
+	regexCache.Lock()
+	defer regexCache.Unlock()
+
+	if regex, ok := regexCache.regexes[pattern]; ok {
+		return regex
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return newError("invalid regular expression: %s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+	}
+	if len(regexCache.regexes) >= regexCacheSize {
+		regexCache.regexes = make(map[string]*object.Regex)
+	}
+	regex := &object.Regex{Regexp: compiled}
+	regexCache.regexes[pattern] = regex
+	return regex
+}
+
+// regexArgs checks that the builtin was called with one of the
+// allowed numbers of arguments, the first being a regex or a
+// pattern string and the second a string, and returns both
+func regexArgs(name string, args []object.Object, want ...int) (*object.Regex, string, *object.Error) {
+	if !wantsArgs(len(args), want) {
+		return nil, "", wrongArgumentCount(len(args), want)
+	}
+
+	var regex *object.Regex
+	switch arg := args[0].(type) {
+	case *object.Regex:
+		regex = arg
+	case *object.String:
+		compiled := compileRegex(arg.Value)
+		if errObj, ok := compiled.(*object.Error); ok {
+			return nil, "", errObj
+		}
+		regex = compiled.(*object.Regex)
+	default:
+		return nil, "", newError("first argument to `%s` must be REGEX or STRING, got %s", name, args[0].Type())
+	}
+
+	s, ok := args[1].(*object.String)
+	if !ok {
+		return nil, "", newError("second argument to `%s` must be STRING, got %s", name, args[1].Type())
+	}
+	return regex, s.Value, nil
+}
+
+// regexLimit returns the optional third argument limiting
+// the number of results, which is -1, meaning no limit,
+// when it wasn't passed
+func regexLimit(name string, args []object.Object) (int, *object.Error) {
+	if len(args) < 3 {
+		return -1, nil
+	}
+	n, ok := args[2].(*object.Integer)
+	if !ok {
+		return 0, newError("third argument to `%s` must be INTEGER, got %s", name, args[2].Type())
+	}
+	return int(n.Value), nil
+}
+
+// wantsArgs returns whether got is one of the allowed argument counts
+func wantsArgs(got int, want []int) bool {
+	for _, w := range want {
+		if got == w {
+			return true
+		}
+	}
+	return false
+}
+
+// wrongArgumentCount returns the error for a builtin
+// called with a number of arguments it doesn't allow
+func wrongArgumentCount(got int, want []int) *object.Error {
+	if len(want) == 2 {
+		return newError("wrong number of arguments. got=%d, want=%d or %d", got, want[0], want[1])
+	}
+	return newError("wrong number of arguments. got=%d, want=%d", got, want[0])
+}
+
+// matchGroups returns the whole match and each capture group
+// of the match at loc, which holds the start and end of each
+func matchGroups(s string, loc []int) []object.Object {
+	groups := make([]object.Object, len(loc)/2)
+	for idx := range groups {
+		start, end := loc[2*idx], loc[2*idx+1]
+		if start < 0 {
+			groups[idx] = NULL
+			continue
+		}
+		groups[idx] = &object.String{Value: s[start:end]}
+	}
+	return groups
+}
+
+// replaceWithFunction replaces every match of the regex in the
+// string with the result of calling fn with the match's groups.
+// Results that aren't strings are replaced with their Inspect output
+func replaceWithFunction(ctx *object.Context, regex *object.Regex, s string, fn object.Object) object.Object {
+	var out strings.Builder
+	last := 0
+	for _, loc := range regex.Regexp.FindAllStringSubmatchIndex(s, -1) {
+		result := applyFunction(ctx, fn, matchGroups(s, loc))
+		if isError(result) {
+			return result
+		}
+		out.WriteString(s[last:loc[0]])
+		if str, ok := result.(*object.String); ok {
+			out.WriteString(str.Value)
+		} else {
+			out.WriteString(result.Inspect())
+		}
+		last = loc[1]
+	}
+	out.WriteString(s[last:])
+	return &object.String{Value: out.String()}
+}
@@ -0,0 +1,423 @@
+package evaluator
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// maxStringLength is the longest string, in bytes, that repeat
+// and the pad functions build, so a script can't exhaust memory
+const maxStringLength = 1 << 28
+
+// stringBuiltins are the built-in functions for working with strings.
+// contains and indexOf also work on arrays, and join turns an
+// array into a string
+var stringBuiltins = map[string]*object.Builtin{
+
+	// split function splits the string around every
+	// separator. An empty separator splits it into characters
+	// example: split("a,b,c", ",") -> [a, b, c]
+	"split": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("split", args, 2)
+			if err != nil {
+				return err
+			}
+			parts := strings.Split(strs[0], strs[1])
+			elements := make([]object.Object, len(parts))
+			for idx, part := range parts {
+				elements[idx] = &object.String{Value: part}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	// join function joins the elements of the array into a string,
+	// putting the optional separator between them. Elements that
+	// aren't strings are joined using their Inspect() value
+	// example: join(["a", "b"], ", ") -> a, b
+	"join": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			elements, ok := iterate(args[0])
+			if !ok {
+				return newError("first argument to `join` must be ARRAY, got %s", args[0].Type())
+			}
+			separator := ""
+			if len(args) == 2 {
+				str, ok := args[1].(*object.String)
+				if !ok {
+					return newError("second argument to `join` must be STRING, got %s", args[1].Type())
+				}
+				separator = str.Value
+			}
+			parts := make([]string, len(elements))
+			for idx, el := range elements {
+				parts[idx] = el.Inspect()
+			}
+			return &object.String{Value: strings.Join(parts, separator)}
+		},
+	},
+
+	// trim function removes whitespace, or the optional
+	// set of characters, from both ends of the string
+	// example: trim("  hi  ") -> hi
+	// example: trim("xxhixx", "x") -> hi
+	"trim": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			return trimString("trim", args, strings.TrimSpace, strings.Trim)
+		},
+	},
+
+	// trimLeft function removes whitespace, or the optional
+	// set of characters, from the start of the string
+	"trimLeft": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			trimSpace := func(s string) string {
+				return strings.TrimLeftFunc(s, isSpace)
+			}
+			return trimString("trimLeft", args, trimSpace, strings.TrimLeft)
+		},
+	},
+
+	// trimRight function removes whitespace, or the optional
+	// set of characters, from the end of the string
+	"trimRight": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			trimSpace := func(s string) string {
+				return strings.TrimRightFunc(s, isSpace)
+			}
+			return trimString("trimRight", args, trimSpace, strings.TrimRight)
+		},
+	},
+
+	// replace function replaces every occurrence of old with new,
+	// or only the first n occurrences when n is given
+	// example: replace("a-b-c", "-", "+") -> a+b+c
+	// example: replace("a-b-c", "-", "+", 1) -> a+b-c
+	"replace": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 3 && len(args) != 4 {
+				return newError("wrong number of arguments. got=%d, want=3 or 4", len(args))
+			}
+			strs, err := stringArgs("replace", args[:3], 3)
+			if err != nil {
+				return err
+			}
+			n := int64(-1)
+			if len(args) == 4 {
+				integer, ok := args[3].(*object.Integer)
+				if !ok {
+					return newError("fourth argument to `replace` must be INTEGER, got %s", args[3].Type())
+				}
+				n = integer.Value
+			}
+			return &object.String{Value: strings.Replace(strs[0], strs[1], strs[2], int(n))}
+		},
+	},
+
+	// contains function checks if the string holds the substring,
+	// or if the array holds an element equal to the value
+	// example: contains("kev lang", "lang") -> true
+	// example: contains([1, 2], 3) -> false
+	"contains": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			idx := indexOf("contains", args[0], args[1])
+			if isError(idx) {
+				return idx
+			}
+			return nativeBoolToBooleanObject(idx.(*object.Integer).Value != -1)
+		},
+	},
+
+	// indexOf function returns the index of the first character
+	// of the substring in the string, or the index of the first
+	// element equal to the value in the array. It returns -1
+	// when there isn't one
+	// example: indexOf("héllo", "l") -> 2
+	// example: indexOf([1, 2], 2) -> 1
+	"indexOf": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			return indexOf("indexOf", args[0], args[1])
+		},
+	},
+
+	// startsWith function checks if the string starts with the prefix
+	"startsWith": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("startsWith", args, 2)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.HasPrefix(strs[0], strs[1]))
+		},
+	},
+
+	// endsWith function checks if the string ends with the suffix
+	"endsWith": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("endsWith", args, 2)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.HasSuffix(strs[0], strs[1]))
+		},
+	},
+
+	// upper function returns the string in upper case
+	"upper": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("upper", args, 1)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToUpper(strs[0])}
+		},
+	},
+
+	// lower function returns the string in lower case
+	"lower": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("lower", args, 1)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToLower(strs[0])}
+		},
+	},
+
+	// repeat function returns the string repeated n times
+	// example: repeat("ab", 3) -> ababab
+	"repeat": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("first argument to `repeat` must be STRING, got %s", args[0].Type())
+			}
+			count, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("second argument to `repeat` must be INTEGER, got %s", args[1].Type())
+			}
+			if count.Value < 0 {
+				return newError("count for `repeat` must not be negative, got %d", count.Value)
+			}
+			if count.Value > 0 && int64(len(str.Value)) > maxStringLength/count.Value {
+				return newError("result of `repeat` is too long, the limit is %d bytes", maxStringLength)
+			}
+			return &object.String{Value: strings.Repeat(str.Value, int(count.Value))}
+		},
+	},
+
+	// padLeft function pads the start of the string with spaces,
+	// or the optional padding, until it's width characters long
+	// example: padLeft("7", 3, "0") -> 007
+	"padLeft": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			return padString("padLeft", args, true)
+		},
+	},
+
+	// padRight function pads the end of the string with spaces,
+	// or the optional padding, until it's width characters long
+	// example: padRight("ab", 4) -> "ab  "
+	"padRight": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			return padString("padRight", args, false)
+		},
+	},
+
+	// chars function returns an array holding
+	// every character of the string as a string
+	// example: chars("héy") -> [h, é, y]
+	"chars": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("chars", args, 1)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, 0, len(strs[0]))
+			for _, r := range strs[0] {
+				elements = append(elements, &object.String{Value: string(r)})
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	// ord function returns the unicode code point
+	// of the single character string passed to it
+	// example: ord("a") -> 97
+	"ord": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, err := stringArgs("ord", args, 1)
+			if err != nil {
+				return err
+			}
+			if utf8.RuneCountInString(strs[0]) != 1 {
+				return newError("argument to `ord` must be a single character, got %q", strs[0])
+			}
+			r, _ := utf8.DecodeRuneInString(strs[0])
+			return &object.Integer{Value: int64(r)}
+		},
+	},
+
+	// chr function returns the single character
+	// string for the unicode code point passed to it
+	// example: chr(97) -> a
+	"chr": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			code, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `chr` must be INTEGER, got %s", args[0].Type())
+			}
+			if code.Value < 0 || code.Value > utf8.MaxRune || !utf8.ValidRune(rune(code.Value)) {
+				return newError("argument to `chr` is not a valid code point, got %d", code.Value)
+			}
+			return &object.String{Value: string(rune(code.Value))}
+		},
+	},
+
+	// format function formats the objects passed to it with
+	// a printf style format string, the same way printf does
+	// example: format("%s is %03d", "kev", 7) -> kev is 007
+	"format": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+			}
+			format, ok := args[0].(*object.String)
+			if !ok {
+				return newError("first argument to `format` must be STRING, got %s", args[0].Type())
+			}
+			return &object.String{Value: formatObjects(format.Value, args[1:])}
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range stringBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// stringArgs checks that the builtin was called with
+// want strings and returns their values
+func stringArgs(name string, args []object.Object, want int) ([]string, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+	strs := make([]string, len(args))
+	for idx, arg := range args {
+		str, ok := arg.(*object.String)
+		if !ok {
+			return nil, newError("arguments to `%s` must be STRING, got %s", name, arg.Type())
+		}
+		strs[idx] = str.Value
+	}
+	return strs, nil
+}
+
+// trimString is shared by trim, trimLeft and trimRight. It trims
+// whitespace with trimSpace, or the given set of characters with trimSet
+func trimString(name string, args []object.Object, trimSpace func(string) string, trimSet func(string, string) string) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	strs, err := stringArgs(name, args, len(args))
+	if err != nil {
+		return err
+	}
+	if len(strs) == 1 {
+		return &object.String{Value: trimSpace(strs[0])}
+	}
+	return &object.String{Value: trimSet(strs[0], strs[1])}
+}
+
+// padString is shared by padLeft and padRight. It repeats the padding
+// at the start or the end of the string until it's width characters long
+func padString(name string, args []object.Object, left bool) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("first argument to `%s` must be STRING, got %s", name, args[0].Type())
+	}
+	width, ok := args[1].(*object.Integer)
+	if !ok {
+		return newError("second argument to `%s` must be INTEGER, got %s", name, args[1].Type())
+	}
+	padding := " "
+	if len(args) == 3 {
+		pad, ok := args[2].(*object.String)
+		if !ok {
+			return newError("third argument to `%s` must be STRING, got %s", name, args[2].Type())
+		}
+		if pad.Value == "" {
+			return newError("padding for `%s` must not be empty", name)
+		}
+		padding = pad.Value
+	}
+
+	if width.Value > maxStringLength {
+		return newError("width for `%s` is too large, the limit is %d", name, maxStringLength)
+	}
+	missing := int(width.Value) - utf8.RuneCountInString(str.Value)
+	if missing <= 0 {
+		return str
+	}
+	pad := []rune(strings.Repeat(padding, missing/utf8.RuneCountInString(padding)+1))[:missing]
+	if left {
+		return &object.String{Value: string(pad) + str.Value}
+	}
+	return &object.String{Value: str.Value + string(pad)}
+}
+
+// indexOf returns the character index of the substring in the string,
+// or the index of the first element of the array equal to the value
+func indexOf(name string, haystack, needle object.Object) object.Object {
+	switch haystack := haystack.(type) {
+	case *object.String:
+		sub, ok := needle.(*object.String)
+		if !ok {
+			return newError("second argument to `%s` must be STRING, got %s", name, needle.Type())
+		}
+		idx := strings.Index(haystack.Value, sub.Value)
+		if idx == -1 {
+			return &object.Integer{Value: -1}
+		}
+		return &object.Integer{Value: int64(utf8.RuneCountInString(haystack.Value[:idx]))}
+	default:
+		elements, ok := iterate(haystack)
+		if !ok {
+			return newError("first argument to `%s` must be STRING or ARRAY, got %s", name, haystack.Type())
+		}
+		for idx, el := range elements {
+			if object.Equal(el, needle) {
+				return &object.Integer{Value: int64(idx)}
+			}
+		}
+		return &object.Integer{Value: -1}
+	}
+}
+
+// isSpace checks if the character is whitespace
+// the same way strings.TrimSpace does
+func isSpace(r rune) bool {
+	return strings.TrimSpace(string(r)) == ""
+}
@@ -0,0 +1,319 @@
+package evaluator
+
+import (
+	"math"
+	"time"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// timeNamespace holds the members of the time namespace,
+// reached with member access, e.g. time.now().
+//
+// Times and durations are objects of their own. A duration can be
+// added to or subtracted from a time, subtracting two times returns
+// the duration between them, and durations can be multiplied and
+// divided by numbers, e.g. time.now() + 2 * time.HOUR. Layouts use
+// Go's reference time, Mon Jan 2 15:04:05 MST 2006, e.g. "2006-01-02".
+// Times are created in UTC unless a time zone is given
+var timeNamespace = map[string]object.Object{
+	"NANOSECOND":  &object.Duration{Value: time.Nanosecond},
+	"MICROSECOND": &object.Duration{Value: time.Microsecond},
+	"MILLISECOND": &object.Duration{Value: time.Millisecond},
+	"SECOND":      &object.Duration{Value: time.Second},
+	"MINUTE":      &object.Duration{Value: time.Minute},
+	"HOUR":        &object.Duration{Value: time.Hour},
+
+	"RFC3339":   &object.String{Value: time.RFC3339},
+	"RFC1123":   &object.String{Value: time.RFC1123},
+	"DATE_TIME": &object.String{Value: time.DateTime},
+	"DATE_ONLY": &object.String{Value: time.DateOnly},
+	"TIME_ONLY": &object.String{Value: time.TimeOnly},
+	"KITCHEN":   &object.String{Value: time.Kitchen},
+
+	// now function returns the current time
+	// from the clock of the program running kev
+	// example: time.now()
+	"now": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			return &object.Time{Value: ctx.Now()}
+		},
+	},
+
+	// unix function returns the UTC time of the Unix timestamp,
+	// which is a number of seconds and can have a fraction
+	// example: time.unix(0) -> 1970-01-01T00:00:00Z
+	"unix": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := numberArgs("unix", args, 1); err != nil {
+				return err
+			}
+			if seconds, ok := args[0].(*object.Integer); ok {
+				return &object.Time{Value: time.Unix(seconds.Value, 0).UTC()}
+			}
+			seconds, _ := object.ToFloat(args[0])
+			whole, fraction := math.Modf(seconds)
+			return &object.Time{Value: time.Unix(int64(whole), int64(math.Round(fraction*1e9))).UTC()}
+		},
+	},
+
+	// unixMilli function returns the UTC time of the
+	// Unix timestamp in milliseconds
+	// example: time.unixMilli(1500) -> 1970-01-01T00:00:01.5Z
+	"unixMilli": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			milliseconds, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `unixMilli` must be INTEGER, got %s", args[0].Type())
+			}
+			return &object.Time{Value: time.UnixMilli(milliseconds.Value).UTC()}
+		},
+	},
+
+	// date function returns the time of the year, month and day,
+	// optionally followed by the hour, minute and second, and then by
+	// the name of a time zone. Values out of range are normalized,
+	// e.g. the 32nd of January is the 1st of February, as long as
+	// the year ends up between 0 and 9999
+	// example: time.date(2024, 5, 1, 12, 30, 0, "Europe/Paris")
+	"date": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			loc := time.UTC
+			if len(args) > 0 {
+				if zone, ok := args[len(args)-1].(*object.String); ok {
+					var errObj *object.Error
+					if loc, errObj = loadLocation(zone.Value); errObj != nil {
+						return errObj
+					}
+					args = args[:len(args)-1]
+				}
+			}
+			if len(args) < 3 || len(args) > 6 {
+				return newError("wrong number of arguments. got=%d, want=3 to 6 numbers and an optional zone", len(args))
+			}
+			parts := make([]int, 6)
+			for idx, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("arguments to `date` must be INTEGER, got %s", arg.Type())
+				}
+				if integer.Value < -maxDatePart || integer.Value > maxDatePart {
+					return newError("arguments to `date` must be between %d and %d, got %d", -maxDatePart, maxDatePart, integer.Value)
+				}
+				parts[idx] = int(integer.Value)
+			}
+			return dateResult("date", time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, loc))
+		},
+	},
+
+	// format function formats the time with the layout,
+	// which defaults to time.RFC3339
+	// example: time.format(t, "2006-01-02") -> 2024-05-01
+	"format": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			t, ok := args[0].(*object.Time)
+			if !ok {
+				return newError("first argument to `format` must be TIME, got %s", args[0].Type())
+			}
+			layout := time.RFC3339
+			if len(args) == 2 {
+				str, ok := args[1].(*object.String)
+				if !ok {
+					return newError("second argument to `format` must be STRING, got %s", args[1].Type())
+				}
+				layout = str.Value
+			}
+			return &object.String{Value: t.Value.Format(layout)}
+		},
+	},
+
+	// parse function parses the string with the layout, which
+	// defaults to time.RFC3339. Strings without a time zone are
+	// read in the zone passed as the third argument, or in UTC
+	// example: time.parse("2024-05-01", "2006-01-02")
+	"parse": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+			}
+			strs, errObj := stringArgs("parse", args, len(args))
+			if errObj != nil {
+				return errObj
+			}
+			layout := time.RFC3339
+			if len(strs) > 1 {
+				layout = strs[1]
+			}
+			loc := time.UTC
+			if len(strs) > 2 {
+				if loc, errObj = loadLocation(strs[2]); errObj != nil {
+					return errObj
+				}
+			}
+			t, err := time.ParseInLocation(layout, strs[0], loc)
+			if err != nil {
+				return newError("could not parse time: %s", err)
+			}
+			return &object.Time{Value: t}
+		},
+	},
+
+	// duration function parses a duration like 1h30m or 250ms.
+	// The units are ns, us, ms, s, m and h
+	// example: time.duration("1h30m") -> 1h30m0s
+	"duration": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			strs, errObj := stringArgs("duration", args, 1)
+			if errObj != nil {
+				return errObj
+			}
+			d, err := time.ParseDuration(strs[0])
+			if err != nil {
+				return newError("could not parse duration: %s", err)
+			}
+			return &object.Duration{Value: d}
+		},
+	},
+
+	// since function returns the duration
+	// from the time until the current time
+	// example: time.since(start)
+	"since": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			t, errObj := timeArg("since", args)
+			if errObj != nil {
+				return errObj
+			}
+			return &object.Duration{Value: ctx.Now().Sub(t)}
+		},
+	},
+
+	// until function returns the duration
+	// from the current time until the time
+	// example: time.until(deadline)
+	"until": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			t, errObj := timeArg("until", args)
+			if errObj != nil {
+				return errObj
+			}
+			return &object.Duration{Value: t.Sub(ctx.Now())}
+		},
+	},
+
+	// addDate function adds the years, months and days to the time,
+	// following the calendar instead of adding fixed durations.
+	// The year of the result must be between 0 and 9999
+	// example: time.addDate(t, 0, 1, 0) -> the same day next month
+	"addDate": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 4 {
+				return newError("wrong number of arguments. got=%d, want=4", len(args))
+			}
+			t, errObj := timeArg("addDate", args[:1])
+			if errObj != nil {
+				return errObj
+			}
+			parts := make([]int, 3)
+			for idx, arg := range args[1:] {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("years, months and days passed to `addDate` must be INTEGER, got %s", arg.Type())
+				}
+				if integer.Value < -maxDatePart || integer.Value > maxDatePart {
+					return newError("years, months and days passed to `addDate` must be between %d and %d, got %d", -maxDatePart, maxDatePart, integer.Value)
+				}
+				parts[idx] = int(integer.Value)
+			}
+			return dateResult("addDate", t.AddDate(parts[0], parts[1], parts[2]))
+		},
+	},
+
+	// inZone function returns the same instant as the time in the
+	// time zone, which is an IANA name like "America/New_York",
+	// "UTC" or "Local" for the zone of the system
+	// example: time.inZone(t, "Asia/Tokyo")
+	"inZone": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			t, errObj := timeArg("inZone", args[:1])
+			if errObj != nil {
+				return errObj
+			}
+			zone, ok := args[1].(*object.String)
+			if !ok {
+				return newError("second argument to `inZone` must be STRING, got %s", args[1].Type())
+			}
+			loc, errObj := loadLocation(zone.Value)
+			if errObj != nil {
+				return errObj
+			}
+			return &object.Time{Value: t.In(loc)}
+		},
+	},
+
+	// utc function returns the same instant as the time in UTC
+	// example: time.utc(t)
+	"utc": &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			t, errObj := timeArg("utc", args)
+			if errObj != nil {
+				return errObj
+			}
+			return &object.Time{Value: t.UTC()}
+		},
+	},
+}
+
+func init() {
+	namespaces["time"] = &object.Module{Name: "time", Exports: timeNamespace}
+}
+
+// timeArg checks that the builtin was
+// called with a single time and returns it
+func timeArg(name string, args []object.Object) (time.Time, *object.Error) {
+	if len(args) != 1 {
+		return time.Time{}, newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	t, ok := args[0].(*object.Time)
+	if !ok {
+		return time.Time{}, newError("argument to `%s` must be TIME, got %s", name, args[0].Type())
+	}
+	return t.Value, nil
+}
+
+// maxDatePart bounds each number passed to date and addDate,
+// so normalizing them can't overflow before the year is checked
+const maxDatePart = 100_000_000
+
+// dateResult returns the time built by the builtin, or an error
+// when its year is outside of what layouts like RFC3339 can hold
+func dateResult(name string, t time.Time) object.Object {
+	if t.Year() < 0 || t.Year() > 9999 {
+		return newError("year of the time built by `%s` must be between 0 and 9999, got %d", name, t.Year())
+	}
+	return &object.Time{Value: t}
+}
+
+// loadLocation returns the time zone with the IANA name. Time
+// zones come from the system's zoneinfo database, so a program
+// embedding kev on a system without one should import
+// time/tzdata, like the kev command does
+func loadLocation(name string) (*time.Location, *object.Error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, newError("unknown time zone %s", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,183 @@
+package evaluator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+// typeBuiltins are the built-in functions for asking
+// what type a value is and converting between types
+var typeBuiltins = map[string]*object.Builtin{
+
+	// type function returns the name of the
+	// type of the object passed to it
+	// example: type(1) -> INTEGER
+	"type": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			return &object.String{Value: string(args[0].Type())}
+		},
+	},
+
+	// int function converts the object passed to it into an
+	// integer. Floats are truncated, booleans become 1 or 0
+	// and strings are parsed as base 10 integers
+	// example: int("42") -> 42
+	// example: int(2.9) -> 2
+	"int": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return arg
+			case *object.Float:
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) ||
+					arg.Value >= math.MaxInt64 || arg.Value < math.MinInt64 {
+					return newError("cannot convert %s to INTEGER", arg.Inspect())
+				}
+				return &object.Integer{Value: int64(arg.Value)}
+			case *object.Boolean:
+				if arg.Value {
+					return &object.Integer{Value: 1}
+				}
+				return &object.Integer{Value: 0}
+			case *object.String:
+				value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 10, 64)
+				if err != nil {
+					return newError("could not parse %q as INTEGER", arg.Value)
+				}
+				return &object.Integer{Value: value}
+			default:
+				return newError("cannot convert %s to INTEGER", args[0].Type())
+			}
+		},
+	},
+
+	// float function converts the object passed to it into
+	// a float. Integers keep their value, booleans become
+	// 1.0 or 0.0 and strings are parsed as floats
+	// example: float("2.5") -> 2.5
+	// example: float(2) -> 2.0
+	"float": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Float:
+				return arg
+			case *object.Integer:
+				return &object.Float{Value: float64(arg.Value)}
+			case *object.Boolean:
+				if arg.Value {
+					return &object.Float{Value: 1}
+				}
+				return &object.Float{Value: 0}
+			case *object.String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return newError("could not parse %q as FLOAT", arg.Value)
+				}
+				return &object.Float{Value: value}
+			default:
+				return newError("cannot convert %s to FLOAT", args[0].Type())
+			}
+		},
+	},
+
+	// str function converts the object passed
+	// to it into its string representation
+	// example: str([1, 2]) -> "[1, 2]"
+	"str": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if str, ok := args[0].(*object.String); ok {
+				return str
+			}
+			return &object.String{Value: args[0].Inspect()}
+		},
+	},
+
+	// bool function converts the object passed to it into
+	// a boolean. Strings are parsed, so only strings like
+	// "true" and "false" can be converted, and every other
+	// object is converted the same way an if condition is
+	// example: bool("false") -> false
+	// example: bool(null) -> false
+	"bool": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if str, ok := args[0].(*object.String); ok {
+				value, err := strconv.ParseBool(strings.TrimSpace(str.Value))
+				if err != nil {
+					return newError("could not parse %q as BOOLEAN", str.Value)
+				}
+				return nativeBoolToBooleanObject(value)
+			}
+			return nativeBoolToBooleanObject(isTruthy(args[0]))
+		},
+	},
+
+	// isInt, isFloat and the rest check
+	// the type of the object passed to them
+	"isInt":    typePredicate(object.INTEGER_OBJ),
+	"isFloat":  typePredicate(object.FLOAT_OBJ),
+	"isNumber": typePredicate(object.INTEGER_OBJ, object.FLOAT_OBJ),
+	"isString": typePredicate(object.STRING_OBJ),
+	"isBool":   typePredicate(object.BOOLEAN_OBJ),
+	"isArray":  typePredicate(object.ARRAY_OBJ),
+	"isHash":   typePredicate(object.HASH_OBJ),
+	"isNull":   typePredicate(object.NULL_OBJ),
+
+	// isFunction function checks if the object passed to it
+	// can be called, which includes builtins and host objects
+	// that implement object.Callable
+	"isFunction": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			switch args[0].(type) {
+			case *object.Function, *object.Builtin, object.Callable:
+				return TRUE
+			default:
+				return FALSE
+			}
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range typeBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// typePredicate returns a builtin that checks if
+// the object passed to it has one of the given types
+func typePredicate(types ...object.ObjectType) *object.Builtin {
+	return &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			for _, t := range types {
+				if args[0].Type() == t {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+	}
+}
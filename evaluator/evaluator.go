@@ -10,15 +10,15 @@ import (
 var (
 	// NULL is a singleton object.Null
 	// representing the null value
-	NULL = &object.Null{}
+	NULL = object.NULL
 
 	// TRUE is a singleton object.Boolean
 	// representing the boolean true
-	TRUE = &object.Boolean{Value: true}
+	TRUE = object.TRUE
 
 	// FALSE is a singleton object.Boolean
 	// representing the boolean false
-	FALSE = &object.Boolean{Value: false}
+	FALSE = object.FALSE
 )
 
 // Eval takes an AST node and evaluates it
@@ -69,6 +69,17 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		env.Set(node.Name.Value, val)
 
+	// If the node is a *ast.ImportStatement,
+	// we import the module and bind it
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+
+	// If the node is a *ast.ExportStatement,
+	// we evaluate the var statement it exports.
+	// The exports are collected once the whole module is evaluated
+	case *ast.ExportStatement:
+		return Eval(node.Statement, env)
+
 	/*
 	 * Expressions
 	 */
@@ -78,11 +89,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 
+	// If the node is a *ast.FloatLiteral,
+	// we return an object.Float
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
 	// If the node is a *ast.Boolean,
 	// we return an object.Boolean
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 
+	// If the node is a *ast.NullLiteral,
+	// we return the NULL singleton
+	case *ast.NullLiteral:
+		return NULL
+
 	// If the node is a *ast.PrefixExpression,
 	// we evaluate the right side of the expression
 	// and pass it to evalPrefixExpression
@@ -101,6 +122,14 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(left) {
 			return left
 		}
+		// the right side of ?? is only
+		// evaluated when the left side is null
+		if node.Operator == "??" {
+			if left != NULL {
+				return left
+			}
+			return Eval(node.Right, env)
+		}
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
@@ -114,31 +143,79 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalIfExpression(node, env)
 
 	// If the node is a *ast.CallExpression,
-	// we evaluate the function and return the result
+	// we evaluate the function and return the result.
+	// An optional call returns null without evaluating
+	// the arguments when the function is null
 	case *ast.CallExpression:
 		function := Eval(node.Function, env)
 		if isError(function) {
 			return function
 		}
+		if node.Optional && function == NULL {
+			return NULL
+		}
 		args := evalExpressions(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return applyFunction(env.Context(), function, args)
 
 	// If the node is a *ast.IndexExpression,
-	// we evaluate the left and index
+	// we evaluate the left and index.
+	// An optional index returns null without
+	// evaluating the index when the left is null
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
+		if node.Optional && left == NULL {
+			return NULL
+		}
 		index := Eval(node.Index, env)
 		if isError(index) {
 			return index
 		}
 		return evalIndexExpression(left, index)
 
+	// If the node is a *ast.MemberExpression,
+	// we evaluate the object and look up the member.
+	// An optional member returns null when the object is null
+	case *ast.MemberExpression:
+		left := Eval(node.Object, env)
+		if isError(left) {
+			return left
+		}
+		if node.Optional && left == NULL {
+			return NULL
+		}
+		return evalMemberExpression(left, node.Property.Value)
+
+	// If the node is a *ast.SliceExpression,
+	// we evaluate the left and whichever bounds are given
+	case *ast.SliceExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		if node.Optional && left == NULL {
+			return NULL
+		}
+		start, end := object.Object(NULL), object.Object(NULL)
+		if node.Start != nil {
+			start = Eval(node.Start, env)
+			if isError(start) {
+				return start
+			}
+		}
+		if node.End != nil {
+			end = Eval(node.End, env)
+			if isError(end) {
+				return end
+			}
+		}
+		return evalSliceExpression(left, start, end)
+
 	/*
 	 * Identifiers
 	 */
@@ -258,15 +335,18 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 // converts a native Go boolean to a object.Boolean.
 // This helps with the singleton pattern
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
-	if input {
-		return TRUE
-	}
-	return FALSE
+	return object.NativeBool(input)
 }
 
 // evaluates the minus prefix operator by checking the right object
 // and returning the negative value
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	// If the right object is an object.Float,
+	// we return a new object.Float with the negative value
+	if float, ok := right.(*object.Float); ok {
+		return &object.Float{Value: -float.Value}
+	}
+
 	// If the right object is not an object.Integer,
 	// we return a newError with the unknown operator
 	if right.Type() != object.INTEGER_OBJ {
@@ -288,12 +368,51 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 		return evalIntegerInfixExpression(operator, left, right)
 	}
 
+	// If either object is a float and the other is a number,
+	// we evaluate the infix expression by calling evalFloatInfixExpression
+	if isNumber(left) && isNumber(right) {
+		return evalFloatInfixExpression(operator, left, right)
+	}
+
+	// If either object is null, == and != check if both are null,
+	// so any value can be compared with null
+	if (left == NULL || right == NULL) && (operator == "==" || operator == "!=") {
+		return nativeBoolToBooleanObject((left == right) == (operator == "=="))
+	}
+
 	// If the left and right objects are booleans,
 	// we evaluate the infix expression by calling evalBooleanInfixExpression
 	if left.Type() == object.BOOLEAN_OBJ && right.Type() == object.BOOLEAN_OBJ {
 		return evalBooleanInfixExpression(operator, left, right)
 	}
 
+	// If the left object defines its own operators,
+	// we let it evaluate the infix expression, and
+	// otherwise we let the right object try
+	if operable, ok := left.(object.Operable); ok {
+		if result, ok := operable.InfixOperator(operator, right); ok {
+			return result
+		}
+	}
+	if operable, ok := right.(object.ReflectedOperable); ok {
+		if result, ok := operable.ReflectedInfixOperator(operator, left); ok {
+			return result
+		}
+	}
+
+	// If either object can be compared, we evaluate the
+	// comparison operators with it, the left one first
+	if comparable, ok := left.(object.Comparable); ok {
+		if result, ok := evalComparableInfixExpression(operator, comparable, right); ok {
+			return result
+		}
+	}
+	if comparable, ok := right.(object.Comparable); ok {
+		if result, ok := evalComparableInfixExpression(operator, reversedComparable{comparable}, left); ok {
+			return result
+		}
+	}
+
 	switch {
 	// If the left and right objects are not the same type,
 	// we return a newError with the type mismatch
@@ -305,6 +424,11 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	// we evaluate the infix expression by calling evalStringInfixExpression
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+
+	// If the left and right objects are arrays or hashes,
+	// we compare them by their contents with evalCollectionInfixExpression
+	case left.Type() == object.ARRAY_OBJ || left.Type() == object.HASH_OBJ:
+		return evalCollectionInfixExpression(operator, left, right)
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
@@ -339,6 +463,47 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+// evaluates the infix expression for floats, where
+// an integer on either side is promoted to a float
+// Example: <leftValue> <operator> <rightValue>
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftValue, _ := object.ToFloat(left)
+	rightValue, _ := object.ToFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftValue + rightValue}
+	case "-":
+		return &object.Float{Value: leftValue - rightValue}
+	case "*":
+		return &object.Float{Value: leftValue * rightValue}
+	case "/":
+		return &object.Float{Value: leftValue / rightValue}
+	case "<", ">", "==", "!=":
+		// compared exactly, so an integer too large to be
+		// a float isn't rounded into being equal to one
+		return compareNumbers(operator, left, right)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// compares two numbers with the operator. NaN isn't
+// ordered, so only != is true when either side is NaN
+func compareNumbers(operator string, left, right object.Object) object.Object {
+	cmp, ok := object.Compare(left, right)
+	switch operator {
+	case "<":
+		return nativeBoolToBooleanObject(ok && cmp < 0)
+	case ">":
+		return nativeBoolToBooleanObject(ok && cmp > 0)
+	case "==":
+		return nativeBoolToBooleanObject(ok && cmp == 0)
+	default:
+		return nativeBoolToBooleanObject(!ok || cmp != 0)
+	}
+}
+
 // evaluates the infix expression for strings
 // by checking the operator and returning the result.
 // Example: <leftValue> <operator> <rightValue>
@@ -348,6 +513,10 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 		return nativeBoolToBooleanObject(left.(*object.String).Value == right.(*object.String).Value)
 	case "!=":
 		return nativeBoolToBooleanObject(left.(*object.String).Value != right.(*object.String).Value)
+	case "<":
+		return nativeBoolToBooleanObject(left.(*object.String).Value < right.(*object.String).Value)
+	case ">":
+		return nativeBoolToBooleanObject(left.(*object.String).Value > right.(*object.String).Value)
 	case "+":
 		leftValue := left.(*object.String).Value
 		rightValue := right.(*object.String).Value
@@ -357,6 +526,32 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 	}
 }
 
+// evaluates the infix expression for arrays and hashes.
+// == and != compare the contents, nested ones included,
+// while < and > order arrays lexicographically.
+// Example: <leftValue> <operator> <rightValue>
+func evalCollectionInfixExpression(operator string, left, right object.Object) object.Object {
+	switch operator {
+	case "==":
+		return nativeBoolToBooleanObject(object.Equal(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
+	case "<", ">":
+		if left.Type() != object.ARRAY_OBJ {
+			break
+		}
+		cmp, ok := object.Compare(left, right)
+		if !ok {
+			return newError("cannot compare: %s %s %s", left.Inspect(), operator, right.Inspect())
+		}
+		if operator == "<" {
+			return nativeBoolToBooleanObject(cmp < 0)
+		}
+		return nativeBoolToBooleanObject(cmp > 0)
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
 // evaluates the infix expression for booleans
 // by checking the operator returning the result.
 // Example: <leftValue> <operator> <rightValue>
@@ -374,6 +569,46 @@ func evalBooleanInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+// evaluates the comparison operators for objects
+// that implement object.Comparable. It returns false
+// if the operator isn't a comparison operator or
+// the objects can't be compared
+func evalComparableInfixExpression(operator string, left object.Comparable, right object.Object) (object.Object, bool) {
+	switch operator {
+	case "==", "!=", "<", ">":
+	default:
+		return nil, false
+	}
+
+	cmp, ok := left.Compare(right)
+	if !ok {
+		return nil, false
+	}
+
+	switch operator {
+	case "==":
+		return nativeBoolToBooleanObject(cmp == 0), true
+	case "!=":
+		return nativeBoolToBooleanObject(cmp != 0), true
+	case "<":
+		return nativeBoolToBooleanObject(cmp < 0), true
+	default:
+		return nativeBoolToBooleanObject(cmp > 0), true
+	}
+}
+
+// reversedComparable compares the other object with the wrapped
+// one, so a comparable object on the right of an operator can
+// be compared as if it was on the left
+type reversedComparable struct {
+	object.Comparable
+}
+
+func (r reversedComparable) Compare(other object.Object) (int, bool) {
+	cmp, ok := r.Comparable.Compare(other)
+	return -cmp, ok
+}
+
 // evaluates the if expression by checking the condition
 // and returning the consequence or alternative
 // based on if the condition is true or not
@@ -404,6 +639,14 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return builtin
 	}
 
+	if value, ok := contextValues[node.Value]; ok {
+		return value(env.Context())
+	}
+
+	if namespace, ok := namespaces[node.Value]; ok {
+		return namespace
+	}
+
 	return newError("identifier not found: " + node.Value)
 }
 
@@ -424,8 +667,12 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case isIndexable(left):
+		return left.(object.Indexable).Index(index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
@@ -443,52 +690,141 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObject.Elements[idx]
 }
 
+// evaluates the string index expression. Strings are indexed
+// by character instead of by byte, and indexing returns the
+// character as a string. Out of range indexes return null
+// the same way they do for arrays
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx := index.(*object.Integer).Value
+
+	if idx < 0 || idx >= int64(len(runes)) {
+		return NULL
+	}
+
+	return &object.String{Value: string(runes[idx])}
+}
+
+// evaluates the slice expression. Slicing an array returns a new
+// array and slicing a string returns a new string, where strings
+// are sliced by character instead of by byte
+func evalSliceExpression(left, start, end object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		low, high, err := sliceBounds(len(left.Elements), start, end)
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, high-low)
+		copy(elements, left.Elements[low:high])
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		low, high, err := sliceBounds(len(runes), start, end)
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: string(runes[low:high])}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+// sliceBounds turns the start and end of a slice into indexes for
+// a sequence of the given length. A missing start or end is NULL.
+// Negative bounds count back from the end of the sequence, and bounds
+// that are out of range are clamped to it, so slicing never fails
+// because of the bounds and returns an empty slice when start >= end
+func sliceBounds(length int, start, end object.Object) (int, int, *object.Error) {
+	bound := func(obj object.Object, missing int) (int, *object.Error) {
+		if obj == NULL {
+			return missing, nil
+		}
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return 0, newError("slice bounds must be INTEGER, got %s", obj.Type())
+		}
+		idx := integer.Value
+		if idx < 0 {
+			idx += int64(length)
+		}
+		if idx < 0 {
+			return 0, nil
+		}
+		if idx > int64(length) {
+			return length, nil
+		}
+		return int(idx), nil
+	}
+
+	low, err := bound(start, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	high, err := bound(end, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	if low > high {
+		low = high
+	}
+	return low, high, nil
+}
+
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
-	key, ok := index.(object.Hashable)
-	if !ok {
+	if _, ok := index.(object.Hashable); !ok {
 		return newError("unusable as hash key: %s", index.Type())
 	}
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	value, ok := hashObject.Get(index)
 	if !ok {
 		return NULL
 	}
-	return pair.Value
+	return value
 }
 
+// evaluates the hash literal by evaluating each key and value
+// in the order they were written, which is the order the
+// resulting object.Hash keeps its pairs in
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := object.NewHash()
 
-	for keyNode, valueNode := range node.Pairs {
+	for _, keyNode := range node.Keys {
 		key := Eval(keyNode, env)
 		if isError(key) {
 			return key
 		}
-		hashKey, ok := key.(object.Hashable)
-		if !ok {
+		if _, ok := key.(object.Hashable); !ok {
 			return newError("unusable as hash key: %s", key.Type())
 		}
-		value := Eval(valueNode, env)
+		value := Eval(node.Pairs[keyNode], env)
 		if isError(value) {
 			return value
 		}
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(key, value)
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 // applyFunction checks if the function is a *object.Function
 // and applies the function by extending the environment or
 // if the function is a *object.Builtin, it applies the function
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// with the context of the environment it was called from
+func applyFunction(ctx *object.Context, fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
+		// every parameter needs an argument,
+		// any extra arguments are ignored
+		if len(args) < len(fn.Parameters) {
+			return newError("wrong number of arguments. got=%d, want=%d", len(args), len(fn.Parameters))
+		}
 		extendedEnv := extendFunctionEnv(fn, args)
 		evaluated := Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
-		return fn.Func(args...)
+		return fn.Func(ctx, args...)
+	case object.Callable:
+		return fn.Call(ctx, args...)
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
@@ -514,6 +850,31 @@ func unwrapReturnValue(obj object.Object) object.Object {
 	return obj
 }
 
+// checks if the object implements object.Indexable
+func isIndexable(obj object.Object) bool {
+	_, ok := obj.(object.Indexable)
+	return ok
+}
+
+// iterate returns the objects held by an array or by an
+// object that implements object.Iterable. It returns false
+// if the object can't be iterated over
+func iterate(obj object.Object) ([]object.Object, bool) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		return obj.Elements, true
+	case object.Iterable:
+		elements := []object.Object{}
+		obj.Iterate(func(el object.Object) bool {
+			elements = append(elements, el)
+			return true
+		})
+		return elements, true
+	default:
+		return nil, false
+	}
+}
+
 // isTruthy checks if the object is truthy
 // by checking if it is NULL, TRUE or FALSE
 func isTruthy(obj object.Object) bool {
@@ -541,3 +902,9 @@ func isError(obj object.Object) bool {
 	}
 	return false
 }
+
+// checks if the object is an integer or a float
+func isNumber(obj object.Object) bool {
+	_, ok := object.ToFloat(obj)
+	return ok
+}
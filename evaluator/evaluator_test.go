@@ -1,11 +1,23 @@
 package evaluator
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	// the time zone tests don't depend on
+	// the system's zoneinfo database
+	_ "time/tzdata"
 
 	"github.com/Gage-McGuire/kev/lexer"
 	"github.com/Gage-McGuire/kev/object"
 	"github.com/Gage-McGuire/kev/parser"
+	"github.com/Gage-McGuire/kev/std"
 )
 
 func testEval(input string) object.Object {
@@ -397,23 +409,48 @@ func TestHashLiterals(t *testing.T) {
 	if !ok {
 		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
 	}
-	expected := map[object.HashKey]int64{
-		(&object.String{Value: "one"}).HashKey():   1,
-		(&object.String{Value: "two"}).HashKey():   2,
-		(&object.String{Value: "three"}).HashKey(): 3,
-		(&object.Integer{Value: 4}).HashKey():      4,
-		TRUE.HashKey():                             5,
-		FALSE.HashKey():                            6,
+	expected := map[object.Object]int64{
+		&object.String{Value: "one"}:   1,
+		&object.String{Value: "two"}:   2,
+		&object.String{Value: "three"}: 3,
+		&object.Integer{Value: 4}:      4,
+		TRUE:                           5,
+		FALSE:                          6,
 	}
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong number of pairs. got=%d", len(result.Pairs))
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong number of pairs. got=%d", result.Len())
 	}
 	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+		value, ok := result.Get(expectedKey)
 		if !ok {
 			t.Errorf("no pair for given key in Pairs")
 		}
-		testIntegerObject(t, pair.Value, expectedValue)
+		testIntegerObject(t, value, expectedValue)
+	}
+}
+
+func TestHashInsertionOrder(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`{"b": 1, "a": 2, "c": 3}`, "{b: 1, a: 2, c: 3}"},
+		{`{3: "c", 1: "a", 2: "b"}`, "{3: c, 1: a, 2: b}"},
+		{`{"b": 1, "a": 2, "b": 3}`, "{b: 3, a: 2}"},
+		{`keys({"z": 1, "y": 2, "x": 3})`, "[z, y, x]"},
+		{`values({"z": 1, "y": 2, "x": 3})`, "[1, 2, 3]"},
+		{`entries({"z": 1, "y": 2})`, "[[z, 1], [y, 2]]"},
+		{`delete({"z": 1, "y": 2, "x": 3}, "y")`, "{z: 1, x: 3}"},
+		{`merge({"z": 1, "y": 2}, {"x": 3, "z": 4})`, "{z: 4, y: 2, x: 3}"},
+	})
+
+	// the same hash must inspect the same way every time
+	input := `{"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6}`
+	first := testEval(input).Inspect()
+	for i := 0; i < 20; i++ {
+		if got := testEval(input).Inspect(); got != first {
+			t.Fatalf("hash inspected differently. first=%s, got=%s", first, got)
+		}
 	}
 }
 
@@ -440,3 +477,1270 @@ func TestHashIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+// vector is a host defined object used to test
+// the host object interfaces
+type vector struct {
+	x, y int64
+}
+
+func (v *vector) Type() object.ObjectType { return "VECTOR" }
+func (v *vector) Inspect() string         { return fmt.Sprintf("<%d, %d>", v.x, v.y) }
+
+func (v *vector) Index(index object.Object) object.Object {
+	str, ok := index.(*object.String)
+	if !ok {
+		return newError("vector index must be STRING, got %s", index.Type())
+	}
+	switch str.Value {
+	case "x":
+		return &object.Integer{Value: v.x}
+	case "y":
+		return &object.Integer{Value: v.y}
+	default:
+		return NULL
+	}
+}
+
+func (v *vector) InfixOperator(operator string, right object.Object) (object.Object, bool) {
+	other, ok := right.(*vector)
+	if !ok || operator != "+" {
+		return nil, false
+	}
+	return &vector{x: v.x + other.x, y: v.y + other.y}, true
+}
+
+func (v *vector) ReflectedInfixOperator(operator string, left object.Object) (object.Object, bool) {
+	factor, ok := left.(*object.Integer)
+	if !ok || operator != "*" {
+		return nil, false
+	}
+	return &vector{x: factor.Value * v.x, y: factor.Value * v.y}, true
+}
+
+// vectors are ordered by their squared length,
+// which can be compared with an integer too
+func (v *vector) Compare(other object.Object) (int, bool) {
+	var otherLength int64
+	switch o := other.(type) {
+	case *vector:
+		otherLength = o.x*o.x + o.y*o.y
+	case *object.Integer:
+		otherLength = o.Value
+	default:
+		return 0, false
+	}
+	length := v.x*v.x + v.y*v.y
+	switch {
+	case length < otherLength:
+		return -1, true
+	case length > otherLength:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// countdown is a host defined object that is both
+// callable and iterable
+type countdown struct {
+	from int64
+}
+
+func (c *countdown) Type() object.ObjectType { return "COUNTDOWN" }
+func (c *countdown) Inspect() string         { return fmt.Sprintf("countdown(%d)", c.from) }
+
+func (c *countdown) Call(ctx *object.Context, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	return &countdown{from: args[0].(*object.Integer).Value}
+}
+
+func (c *countdown) Iterate(yield func(object.Object) bool) {
+	for i := c.from; i > 0; i-- {
+		if !yield(&object.Integer{Value: i}) {
+			return
+		}
+	}
+}
+
+func TestHostObjects(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`a["x"]`, "1"},
+		{`b["y"]`, "4"},
+		{`a["z"]`, "null"},
+		{`a + b`, "<4, 6>"},
+		{`(a + b)["x"]`, "4"},
+		{`a == a`, "true"},
+		{`a != b`, "true"},
+		{`a < b`, "true"},
+		{`a > b`, "false"},
+		{`a - b`, "ERROR: unknown operator: VECTOR - VECTOR"},
+		{`a + 1`, "ERROR: type mismatch: VECTOR + INTEGER"},
+		{`2 * a`, "<2, 4>"},
+		{`2 * a + b`, "<5, 8>"},
+		{`a * 2`, "ERROR: type mismatch: VECTOR * INTEGER"},
+		{`1 - a`, "ERROR: type mismatch: INTEGER - VECTOR"},
+		{`a < 6`, "true"},
+		{`6 > a`, "true"},
+		{`6 < a`, "false"},
+		{`5 == a`, "true"},
+		{`"a" == a`, "ERROR: type mismatch: STRING == VECTOR"},
+		{`a[1]`, "ERROR: vector index must be STRING, got INTEGER"},
+		{`countdown(3)`, "countdown(3)"},
+		{`len(countdown(3))`, "3"},
+		{`first(countdown(3))`, "3"},
+		{`last(countdown(3))`, "1"},
+		{`tail(countdown(3))`, "[2, 1]"},
+		{`a(1)`, "ERROR: not a function: VECTOR"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("a", &vector{x: 1, y: 2})
+		env.Set("b", &vector{x: 3, y: 4})
+		env.Set("countdown", &countdown{})
+
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		evaluated := Eval(p.ParseProgram(), env)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%s, want=%s", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestIOBuiltins(t *testing.T) {
+	tests := []struct {
+		input          string
+		stdin          string
+		expectedStdout string
+		expectedStderr string
+	}{
+		{`print("a", 1, true)`, "", "a\n1\ntrue\n", ""},
+		{`println("a", 1, [1, 2])`, "", "a 1 [1, 2]\n", ""},
+		{`println()`, "", "\n", ""},
+		{`printf("%s is %d, %5.5s|%t", "x", 42, "padded", false)`, "", "x is 42, padde|false", ""},
+		{`printf("%v", {"a": 1})`, "", "{a: 1}", ""},
+		{`eprint("oops", 1)`, "", "", "oops 1\n"},
+		{`print(input("name? "))`, "kev\n", "name? kev\n", ""},
+		{`var a = input(); var b = input(); var c = input(); println(a, b, c)`, "one\r\ntwo", "one two null\n", ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		ctx := object.NewContext()
+		ctx.Stdout = &stdout
+		ctx.Stderr = &stderr
+		ctx.Stdin = strings.NewReader(tt.stdin)
+		env := object.NewEnvironment()
+		env.SetContext(ctx)
+
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		evaluated := Eval(p.ParseProgram(), env)
+		if isError(evaluated) {
+			t.Errorf("%q returned error: %s", tt.input, evaluated.Inspect())
+			continue
+		}
+		if stdout.String() != tt.expectedStdout {
+			t.Errorf("wrong stdout for %q. got=%q, want=%q", tt.input, stdout.String(), tt.expectedStdout)
+		}
+		if stderr.String() != tt.expectedStderr {
+			t.Errorf("wrong stderr for %q. got=%q, want=%q", tt.input, stderr.String(), tt.expectedStderr)
+		}
+	}
+}
+
+func TestContextIsShared(t *testing.T) {
+	var stdout bytes.Buffer
+	ctx := object.NewContext()
+	ctx.Stdout = &stdout
+	env := object.NewEnvironment()
+	env.SetContext(ctx)
+
+	input := `
+	var greet = func(name) { print("hi " + name) };
+	var twice = func(f, x) { f(x); f(x) };
+	twice(greet, "kev");
+	`
+	l := lexer.New(input)
+	p := parser.New(l)
+	Eval(p.ParseProgram(), env)
+	if stdout.String() != "hi kev\nhi kev\n" {
+		t.Errorf("wrong stdout. got=%q", stdout.String())
+	}
+}
+
+// testBuiltins evaluates each input and compares the Inspect()
+// output of the result, errors included, with the expected string
+func testBuiltins(t *testing.T, tests []struct {
+	input    string
+	expected string
+}) {
+	t.Helper()
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%q evaluated to nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%s, want=%s", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestArrayBuiltins(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`len([1, 2, 3])`, "3"},
+		{`first([1, 2, 3])`, "1"},
+		{`first([])`, "null"},
+		{`first(1)`, "ERROR: argument to `first` must be ARRAY, got INTEGER"},
+		{`last([1, 2, 3])`, "3"},
+		{`last([])`, "null"},
+		{`last(1)`, "ERROR: argument to `last` must be ARRAY, got INTEGER"},
+		{`tail([1, 2, 3])`, "[2, 3]"},
+		{`tail([])`, "null"},
+		{`tail(1)`, "ERROR: argument to `tail` must be ARRAY, got INTEGER"},
+		{`push([1, 2], 3)`, "[1, 2, 3]"},
+		{`var a = [1]; push(a, 2); a`, "[1]"},
+		{`push(1, 2)`, "ERROR: argument to `push` must be ARRAY, got INTEGER"},
+	})
+}
+
+func TestHashBuiltins(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`len({})`, "0"},
+		{`len({"a": 1, "b": 2})`, "2"},
+		{`keys({"a": 1})`, "[a]"},
+		{`keys({})`, "[]"},
+		{`len(keys({"a": 1, "b": 2, "c": 3}))`, "3"},
+		{`keys([1])`, "ERROR: argument to `keys` must be HASH, got ARRAY"},
+		{`values({"a": 1})`, "[1]"},
+		{`len(values({"a": 1, "b": 2, "c": 3}))`, "3"},
+		{`values(1)`, "ERROR: argument to `values` must be HASH, got INTEGER"},
+		{`entries({"a": 1})`, "[[a, 1]]"},
+		{`entries({})`, "[]"},
+		{`entries("a")`, "ERROR: argument to `entries` must be HASH, got STRING"},
+		{`has({"a": 1}, "a")`, "true"},
+		{`has({"a": 1}, "b")`, "false"},
+		{`has({1: 1}, 1)`, "true"},
+		{`has({1: 1}, 1.0)`, "true"},
+		{`has({1.5: 1}, 1)`, "false"},
+		{`{1: "a"}[1.0]`, "a"},
+		{`{2.0: "b"}[2]`, "b"},
+		{`len({1: "a", 1.0: "b"})`, "1"},
+		{`has({"a": 1}, [])`, "ERROR: unusable as hash key: ARRAY"},
+		{`has([], 1)`, "ERROR: argument to `has` must be HASH, got ARRAY"},
+		{`delete({"a": 1, "b": 2}, "a")`, "{b: 2}"},
+		{`delete({"a": 1}, "z")`, "{a: 1}"},
+		{`var h = {"a": 1}; delete(h, "a"); h`, "{a: 1}"},
+		{`delete({"a": 1}, func(x) { x })`, "ERROR: unusable as hash key: FUNCTION"},
+		{`delete(1, 1)`, "ERROR: argument to `delete` must be HASH, got INTEGER"},
+		{`merge({"a": 1}, {"a": 2})`, "{a: 2}"},
+		{`merge({"a": 1}, {"b": 2}, {"c": 3})["c"]`, "3"},
+		{`len(merge({"a": 1}, {"b": 2}, {"a": 3}))`, "2"},
+		{`var h = {"a": 1}; merge(h, {"a": 2}); h`, "{a: 1}"},
+		{`merge()`, "ERROR: wrong number of arguments. got=0, want at least 1"},
+		{`merge({}, [])`, "ERROR: arguments to `merge` must be HASH, got ARRAY"},
+	})
+}
+
+func TestStructuralComparison(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2, 3] == [1, 2, 3]`, "true"},
+		{`[1, 2, 3] == [1, 2]`, "false"},
+		{`[1, 2, 3] != [1, 2, 4]`, "true"},
+		{`[] == []`, "true"},
+		{`[1, "a", true] == [1, "a", true]`, "true"},
+		{`[1, "a"] == ["a", 1]`, "false"},
+		{`[[1, 2], [3]] == [[1, 2], [3]]`, "true"},
+		{`[[1, 2], [3]] == [[1, 2], [4]]`, "false"},
+		{`[{"a": [1]}] == [{"a": [1]}]`, "true"},
+		{`var a = [1]; a == a`, "true"},
+		{`{"a": 1, "b": 2} == {"b": 2, "a": 1}`, "true"},
+		{`{"a": 1} == {"a": 2}`, "false"},
+		{`{"a": 1} == {"a": 1, "b": 2}`, "false"},
+		{`{"a": {"b": [1, 2]}} == {"a": {"b": [1, 2]}}`, "true"},
+		{`{"a": {"b": [1, 2]}} != {"a": {"b": [1, 3]}}`, "true"},
+		{`{1: "one"} == {"1": "one"}`, "false"},
+		{`"a" < "b"`, "true"},
+		{`"b" < "a"`, "false"},
+		{`"abc" > "abd"`, "false"},
+		{`"ab" < "abc"`, "true"},
+		{`"Z" < "a"`, "true"},
+		{`[1, 2] < [1, 3]`, "true"},
+		{`[1, 2] > [1, 3]`, "false"},
+		{`[1, 2] < [1, 2, 0]`, "true"},
+		{`[2] > [1, 9, 9]`, "true"},
+		{`[] < [1]`, "true"},
+		{`["b"] > ["a", "z"]`, "true"},
+		{`[[1, 2], 3] < [[1, 3], 0]`, "true"},
+		{`[false] < [true]`, "true"},
+		{`[1, 2] < [1, 2]`, "false"},
+		{`[1] < ["a"]`, "ERROR: cannot compare: [1] < [a]"},
+		{`{"a": 1} < {"a": 2}`, "ERROR: unknown operator: HASH < HASH"},
+		{`[1] + [2]`, "ERROR: unknown operator: ARRAY + ARRAY"},
+		{`[1] == {"a": 1}`, "ERROR: type mismatch: ARRAY == HASH"},
+	})
+}
+
+func TestCollectionBuiltins(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`map([1, 2, 3], func(x) { x * 2 })`, "[2, 4, 6]"},
+		{`map([], func(x) { x * 2 })`, "[]"},
+		{`map([1, "a"], func(x) { x + 1 })`, "ERROR: type mismatch: STRING + INTEGER"},
+		{`map([1], len)`, "ERROR: argument to `len` not supported, got INTEGER"},
+		{`map(["ab", "c"], len)`, "[2, 1]"},
+		{`map(1, len)`, "ERROR: first argument to `map` must be ARRAY, got INTEGER"},
+		{`map([1], 1)`, "ERROR: not a function: INTEGER"},
+		{`map([1], func(x, y) { x })`, "ERROR: wrong number of arguments. got=1, want=2"},
+		{`filter([1, 2, 3, 4], func(x) { x > 2 })`, "[3, 4]"},
+		{`filter([1, 2], func(x) { false })`, "[]"},
+		{`filter("a", len)`, "ERROR: first argument to `filter` must be ARRAY, got STRING"},
+		{`reduce([1, 2, 3], func(acc, x) { acc + x }, 10)`, "16"},
+		{`reduce([1, 2, 3], func(acc, x) { acc * x })`, "6"},
+		{`reduce([], func(acc, x) { acc + x })`, "null"},
+		{`reduce([], func(acc, x) { acc + x }, 0)`, "0"},
+		{`reduce(["a", "b"], func(acc, x) { push(acc, x) }, [])`, "[a, b]"},
+		{`reduce([1], func(acc, x) { acc + x })`, "1"},
+		{`reduce([1])`, "ERROR: wrong number of arguments. got=1, want=2 or 3"},
+		{`sort([3, 1, 2])`, "[1, 2, 3]"},
+		{`sort(["b", "c", "a"])`, "[a, b, c]"},
+		{`sort([[2], [1, 5], [1]])`, "[[1], [1, 5], [2]]"},
+		{`sort([1, 2, 3], func(a, b) { a > b })`, "[3, 2, 1]"},
+		{`sort([1, 2, 3], func(a, b) { b - a })`, "[3, 2, 1]"},
+		{`sort([["b", 1], ["a", 1], ["c", 0]], func(a, b) { a[1] < b[1] })`, "[[c, 0], [b, 1], [a, 1]]"},
+		{`var a = [2, 1]; sort(a); a`, "[2, 1]"},
+		{`sort([1, "a"])`, "ERROR: cannot compare: a < 1"},
+		{`sort([1, 2], func(a, b) { "x" })`, "ERROR: comparator for `sort` must return BOOLEAN or INTEGER, got STRING"},
+		{`sort([1, 2], func(a, b) { a + true })`, "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{`find([1, 2, 3], func(x) { x > 1 })`, "2"},
+		{`find([1, 2, 3], func(x) { x > 5 })`, "null"},
+		{`any([1, 2, 3], func(x) { x > 2 })`, "true"},
+		{`any([1, 2, 3], func(x) { x > 3 })`, "false"},
+		{`any([])`, "false"},
+		{`any([false, 1])`, "true"},
+		{`all([1, 2, 3], func(x) { x > 0 })`, "true"},
+		{`all([1, 2, 3], func(x) { x > 1 })`, "false"},
+		{`all([])`, "true"},
+		{`all([true, false])`, "false"},
+		{`all(1)`, "ERROR: first argument to `all` must be ARRAY, got INTEGER"},
+		{`zip([1, 2], ["a", "b"])`, "[[1, a], [2, b]]"},
+		{`zip([1, 2, 3], ["a"], [true, false])`, "[[1, a, true]]"},
+		{`zip([1], 2)`, "ERROR: arguments to `zip` must be ARRAY, got INTEGER"},
+		{`enumerate(["a", "b"])`, "[[0, a], [1, b]]"},
+		{`enumerate([])`, "[]"},
+		{`flatten([1, [2, [3, [4]]], []])`, "[1, 2, 3, 4]"},
+		{`flatten([1, [2, [3, [4]]]], 1)`, "[1, 2, [3, [4]]]"},
+		{`flatten([1, [2]], 0)`, "[1, [2]]"},
+		{`flatten(1)`, "ERROR: first argument to `flatten` must be ARRAY, got INTEGER"},
+		{`range(4)`, "[0, 1, 2, 3]"},
+		{`range(2, 5)`, "[2, 3, 4]"},
+		{`range(1, 10, 3)`, "[1, 4, 7]"},
+		{`range(5, 0, -2)`, "[5, 3, 1]"},
+		{`range(0)`, "[]"},
+		{`range(5, 1)`, "[]"},
+		{`range(9223372036854775806, 9223372036854775807, 2)`, "[9223372036854775806]"},
+		{`range(-9223372036854775807, -9223372036854775807 - 1, -5)`, "[-9223372036854775807]"},
+		{`len(range(-1, 9223372036854775807, 4611686018427387904))`, "2"},
+		{`range(9223372036854775807)`, "ERROR: `range` is too long, the limit is 16777216 elements"},
+		{`range(1, 2, 0)`, "ERROR: step of `range` must not be 0"},
+		{`range("a")`, "ERROR: arguments to `range` must be INTEGER, got STRING"},
+		{`map(range(3), func(i) { map(range(i), func(j) { j }) })`, "[[], [0], [0, 1]]"},
+		{`var add = func(x) { func(y) { x + y } }; map([1, 2], add(10))`, "[11, 12]"},
+	})
+}
+
+func TestStringBuiltins(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`split("a,b,c", ",")`, "[a, b, c]"},
+		{`split("abc", "")`, "[a, b, c]"},
+		{`split("abc", ",")`, "[abc]"},
+		{`split(1, ",")`, "ERROR: arguments to `split` must be STRING, got INTEGER"},
+		{`join(["a", "b", "c"], ", ")`, "a, b, c"},
+		{`join(["a", 1, true])`, "a1true"},
+		{`join([], "-")`, ""},
+		{`join("a", "-")`, "ERROR: first argument to `join` must be ARRAY, got STRING"},
+		{`trim("  hi  ")`, "hi"},
+		{`trim("xxhixx", "x")`, "hi"},
+		{`trimLeft("  hi  ")`, "hi  "},
+		{`trimRight("  hi  ")`, "  hi"},
+		{`trimLeft("xxhixx", "x")`, "hixx"},
+		{`trimRight("xxhixx", "x")`, "xxhi"},
+		{`trim()`, "ERROR: wrong number of arguments. got=0, want=1 or 2"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`replace("a-b-c", "-", "+", 1)`, "a+b-c"},
+		{`replace("a-b-c", "-", "+", "1")`, "ERROR: fourth argument to `replace` must be INTEGER, got STRING"},
+		{`contains("kev lang", "lang")`, "true"},
+		{`contains("kev lang", "go")`, "false"},
+		{`contains([1, [2], "a"], [2])`, "true"},
+		{`contains([1, 2], 3)`, "false"},
+		{`contains(1, 1)`, "ERROR: first argument to `contains` must be STRING or ARRAY, got INTEGER"},
+		{`indexOf("hello", "l")`, "2"},
+		{`indexOf("héllo", "l")`, "2"},
+		{`indexOf("hello", "z")`, "-1"},
+		{`indexOf([1, 2, 3], 3)`, "2"},
+		{`indexOf([1, 2, 3], 4)`, "-1"},
+		{`indexOf("hello", 1)`, "ERROR: second argument to `indexOf` must be STRING, got INTEGER"},
+		{`startsWith("kevlang", "kev")`, "true"},
+		{`startsWith("kevlang", "lang")`, "false"},
+		{`endsWith("kevlang", "lang")`, "true"},
+		{`endsWith("kevlang", 1)`, "ERROR: arguments to `endsWith` must be STRING, got INTEGER"},
+		{`upper("Kev")`, "KEV"},
+		{`lower("Kev")`, "kev"},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`repeat("ab", -1)`, "ERROR: count for `repeat` must not be negative, got -1"},
+		{`repeat("ab", 9223372036854775807)`, "ERROR: result of `repeat` is too long, the limit is 268435456 bytes"},
+		{`repeat("", 9223372036854775807)`, ""},
+		{`padLeft("7", 3, "0")`, "007"},
+		{`padLeft("7", 4, "ab")`, "aba7"},
+		{`padRight("ab", 4) + "|"`, "ab  |"},
+		{`padLeft("héllo", 5)`, "héllo"},
+		{`padLeft("abc", 2)`, "abc"},
+		{`padRight("a", 9223372036854775807)`, "ERROR: width for `padRight` is too large, the limit is 268435456"},
+		{`padLeft("a", 2, "")`, "ERROR: padding for `padLeft` must not be empty"},
+		{`format("%s is %03d", "kev", 7)`, "kev is 007"},
+		{`format("%v %t", [1, 2], true)`, "[1, 2] true"},
+		{`format(1)`, "ERROR: first argument to `format` must be STRING, got INTEGER"},
+	})
+}
+
+func TestSliceExpressions(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4][2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4][-2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:-1]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4][-10:2]", "[1, 2]"},
+		{"[1, 2, 3, 4][2:10]", "[3, 4]"},
+		{"[1, 2, 3, 4][3:1]", "[]"},
+		{"[][0:1]", "[]"},
+		{"var a = [1, 2, 3]; var b = a[:]; a == b", "true"},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[-3:]`, "llo"},
+		{`"héllo"[0:2]`, "hé"},
+		{`"hello"[4:2]`, ""},
+		{`[1, 2][1:"a"]`, "ERROR: slice bounds must be INTEGER, got STRING"},
+		{`{"a": 1}[0:1]`, "ERROR: slice operator not supported: HASH"},
+	})
+}
+
+func TestStringIndexing(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`"abc"[0]`, "a"},
+		{`"abc"[2]`, "c"},
+		{`"abc"[1 + 1]`, "c"},
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[2]`, "l"},
+		{`"abc"[3]`, "null"},
+		{`"abc"[-1]`, "null"},
+		{`var s = "héllo"; s[len(s) - 1]`, "o"},
+		{`len("héllo")`, "5"},
+		{`chars("héy")`, "[h, é, y]"},
+		{`chars("")`, "[]"},
+		{`join(chars("abc"), "-")`, "a-b-c"},
+		{`ord("a")`, "97"},
+		{`ord("é")`, "233"},
+		{`ord("ab")`, `ERROR: argument to ` + "`ord`" + ` must be a single character, got "ab"`},
+		{`ord("")`, `ERROR: argument to ` + "`ord`" + ` must be a single character, got ""`},
+		{`chr(97)`, "a"},
+		{`chr(233)`, "é"},
+		{`chr(ord("a") + 1)`, "b"},
+		{`chr(-1)`, "ERROR: argument to `chr` is not a valid code point, got -1"},
+		{`chr("a")`, "ERROR: argument to `chr` must be INTEGER, got STRING"},
+	})
+}
+
+func TestFloatExpressions(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{"2.5", "2.5"},
+		{"-2.5", "-2.5"},
+		{"1.5 + 1.5", "3.0"},
+		{"1.5 * 2", "3.0"},
+		{"1 + 0.5", "1.5"},
+		{"7 / 2.0", "3.5"},
+		{"1 - 0.25", "0.75"},
+		{"0.1 < 0.2", "true"},
+		{"2 > 1.5", "true"},
+		{"1 == 1.0", "true"},
+		{"1.5 != 1.5", "false"},
+		{"9007199254740993 == 9007199254740992.0", "false"},
+		{"9007199254740993 > 9007199254740992.0", "true"},
+		{"9007199254740992.0 < 9007199254740993", "true"},
+		{"9007199254740993 != 9007199254740992.0", "true"},
+		{"[1, 2.0] == [1.0, 2]", "true"},
+		{"sort([2.5, 1, 2])", "[1, 2, 2.5]"},
+		{`format("%.2f", 3.14159)`, "3.14"},
+		{`1.5 + "a"`, "ERROR: type mismatch: FLOAT + STRING"},
+	})
+}
+
+func TestTypeBuiltins(t *testing.T) {
+	// the type is checked too, since the results of
+	// int("42") and str(42) both inspect as 42
+	tests := []struct {
+		input    string
+		expected string
+		objType  object.ObjectType
+	}{
+		{`type(1)`, "INTEGER", object.STRING_OBJ},
+		{`type(1.5)`, "FLOAT", object.STRING_OBJ},
+		{`type("a")`, "STRING", object.STRING_OBJ},
+		{`type(true)`, "BOOLEAN", object.STRING_OBJ},
+		{`type([])`, "ARRAY", object.STRING_OBJ},
+		{`type({})`, "HASH", object.STRING_OBJ},
+		{`type(first([]))`, "NULL", object.STRING_OBJ},
+		{`type(func(x) { x })`, "FUNCTION", object.STRING_OBJ},
+		{`type(len)`, "BUILTIN", object.STRING_OBJ},
+		{`int("42")`, "42", object.INTEGER_OBJ},
+		{`int(" -7 ")`, "-7", object.INTEGER_OBJ},
+		{`int(2.9)`, "2", object.INTEGER_OBJ},
+		{`int(-2.9)`, "-2", object.INTEGER_OBJ},
+		{`int(true)`, "1", object.INTEGER_OBJ},
+		{`int(5)`, "5", object.INTEGER_OBJ},
+		{`int("5" + "1") + 1`, "52", object.INTEGER_OBJ},
+		{`int("abc")`, `ERROR: could not parse "abc" as INTEGER`, object.ERROR_OBJ},
+		{`int("1.5")`, `ERROR: could not parse "1.5" as INTEGER`, object.ERROR_OBJ},
+		{`int([])`, "ERROR: cannot convert ARRAY to INTEGER", object.ERROR_OBJ},
+		{`float("2.5")`, "2.5", object.FLOAT_OBJ},
+		{`float(2)`, "2.0", object.FLOAT_OBJ},
+		{`float(false)`, "0.0", object.FLOAT_OBJ},
+		{`float("x")`, `ERROR: could not parse "x" as FLOAT`, object.ERROR_OBJ},
+		{`float({})`, "ERROR: cannot convert HASH to FLOAT", object.ERROR_OBJ},
+		{`str(42)`, "42", object.STRING_OBJ},
+		{`str(5) + "1"`, "51", object.STRING_OBJ},
+		{`str([1, "a"])`, "[1, a]", object.STRING_OBJ},
+		{`str(1.0)`, "1.0", object.STRING_OBJ},
+		{`bool("true")`, "true", object.BOOLEAN_OBJ},
+		{`bool("false")`, "false", object.BOOLEAN_OBJ},
+		{`bool("yes")`, `ERROR: could not parse "yes" as BOOLEAN`, object.ERROR_OBJ},
+		{`bool(0)`, "true", object.BOOLEAN_OBJ},
+		{`bool(first([]))`, "false", object.BOOLEAN_OBJ},
+		{`isInt(1)`, "true", object.BOOLEAN_OBJ},
+		{`isInt(1.0)`, "false", object.BOOLEAN_OBJ},
+		{`isFloat(1.0)`, "true", object.BOOLEAN_OBJ},
+		{`isNumber(1)`, "true", object.BOOLEAN_OBJ},
+		{`isNumber("1")`, "false", object.BOOLEAN_OBJ},
+		{`isString("1")`, "true", object.BOOLEAN_OBJ},
+		{`isBool(false)`, "true", object.BOOLEAN_OBJ},
+		{`isArray([])`, "true", object.BOOLEAN_OBJ},
+		{`isHash({})`, "true", object.BOOLEAN_OBJ},
+		{`isNull(first([]))`, "true", object.BOOLEAN_OBJ},
+		{`isNull(0)`, "false", object.BOOLEAN_OBJ},
+		{`isFunction(len)`, "true", object.BOOLEAN_OBJ},
+		{`isFunction(func() { 1 })`, "true", object.BOOLEAN_OBJ},
+		{`isFunction(1)`, "false", object.BOOLEAN_OBJ},
+		{`isInt()`, "ERROR: wrong number of arguments. got=0, want=1", object.ERROR_OBJ},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%q evaluated to nil", tt.input)
+			continue
+		}
+		if evaluated.Type() != tt.objType {
+			t.Errorf("wrong type for %q. got=%s, want=%s", tt.input, evaluated.Type(), tt.objType)
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%s, want=%s", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestNullAndOptionalChaining(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`null`, "null"},
+		{`var x = null; x`, "null"},
+		{`null == null`, "true"},
+		{`null != null`, "false"},
+		{`1 == null`, "false"},
+		{`null != "a"`, "true"},
+		{`!null`, "true"},
+		{`if (null) { 1 } else { 2 }`, "2"},
+		{`null ?? 5`, "5"},
+		{`0 ?? 5`, "0"},
+		{`false ?? 5`, "false"},
+		{`null ?? null ?? 3`, "3"},
+		{`var h = {"a": 1}; h["b"] ?? "default"`, "default"},
+		{`1 ?? undefinedName`, "1"},
+		{`null ?? undefinedName`, "ERROR: identifier not found: undefinedName"},
+		{`var h = {"a": {"b": 2}}; h?.["a"]?.["b"]`, "2"},
+		{`var h = {"a": {"b": 2}}; h?.["x"]?.["b"]`, "null"},
+		{`var h = {"a": {"b": 2}}; h?.["x"]?.["b"] ?? 0`, "0"},
+		{`null?.[undefinedName]`, "null"},
+		{`null?.[1:2]`, "null"},
+		{`[1, 2, 3]?.[1:]`, "[2, 3]"},
+		{`var f = null; f?.(undefinedName)`, "null"},
+		{`var f = func(x) { x * 2 }; f?.(4)`, "8"},
+		{`var h = {"double": func(x) { x * 2 }}; h["triple"]?.(1) ?? -1`, "-1"},
+		{`null[0]`, "ERROR: index operator not supported: NULL"},
+		{`null + 1`, "ERROR: type mismatch: NULL + INTEGER"},
+	})
+}
+
+// writeModules writes each file into a new temporary
+// directory and returns the path of the directory
+func writeModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestModules(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib/math.kev": `
+			print("loading math");
+			var secret = 42;
+			export var pi = 3;
+			export var square = func(x) { x * x };
+			export var area = func(r) { pi * square(r) };
+		`,
+		"lib/shapes.kev": `
+			import "math.kev" as m;
+			export var circle = func(r) { m.area(r) };
+		`,
+		"cycle/a.kev": `import "b.kev" as b; export var a = 1;`,
+		"cycle/b.kev": `import "a.kev" as a; export var b = 2;`,
+		"broken.kev":  `var = ;`,
+		"failing.kev": `export var x = missing;`,
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib/math.kev" as math; math.square(4)`, "16"},
+		{`import "lib/math.kev" as math; math["pi"]`, "3"},
+		{`import "lib/math.kev" as math; math`, "module(math.kev)"},
+		{`import { square, pi as p } from "lib/math.kev"; square(p)`, "9"},
+		{`import "lib/shapes.kev" as shapes; shapes.circle(2)`, "12"},
+		{`import "lib/math.kev" as math; math.secret`, "ERROR: module math.kev has no export secret"},
+		{`import { secret } from "lib/math.kev"; secret`, "ERROR: module math.kev has no export secret"},
+		{`import "missing.kev" as m; m`, "ERROR: module not found: missing.kev"},
+		{`import "cycle/a.kev" as a; a`, "ERROR: import cycle: a.kev -> b.kev -> a.kev"},
+		{`import "broken.kev" as b; b`, "ERROR: could not parse module broken.kev: expected next token to be IDENT, got =; no prefix parse function for = found"},
+		{`import "failing.kev" as f; f`, "ERROR: identifier not found: missing"},
+		{`var f = func() { import "lib/math.kev" as m; m.pi }; f()`, "3"},
+		{`var h = {"a": {"b": 1}}; h.a.b`, "1"},
+		{`var h = {"a": 1}; h.missing`, "null"},
+		{`var h = null; h?.a?.b ?? "none"`, "none"},
+		{`1.foo`, "ERROR: member access not supported: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		ctx := object.NewContext()
+		ctx.Stdout = &stdout
+		env := object.NewEnvironment()
+		env.SetContext(ctx)
+		env.SetFile(filepath.Join(dir, "main.kev"))
+
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+		evaluated := Eval(program, env)
+
+		got := ""
+		switch result := evaluated.(type) {
+		case nil:
+			got = "nil"
+		case *object.Error:
+			got = "ERROR: " + result.Message
+		default:
+			got = result.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. got=%s, want=%s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestModulesAreEvaluatedOnce(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"counter.kev": `print("loading"); export var value = 1;`,
+		"user.kev":    `import "counter.kev" as c; export var value = c.value + 1;`,
+	})
+
+	var stdout bytes.Buffer
+	ctx := object.NewContext()
+	ctx.Stdout = &stdout
+	env := object.NewEnvironment()
+	env.SetContext(ctx)
+	env.SetFile(filepath.Join(dir, "main.kev"))
+
+	input := `
+	import "counter.kev" as a;
+	import "./counter.kev" as b;
+	import "user.kev" as u;
+	[a.value, b.value, u.value]
+	`
+	l := lexer.New(input)
+	p := parser.New(l)
+	evaluated := Eval(p.ParseProgram(), env)
+	if evaluated.Inspect() != "[1, 1, 2]" {
+		t.Errorf("wrong result. got=%s", evaluated.Inspect())
+	}
+	if stdout.String() != "loading\n" {
+		t.Errorf("module was not evaluated exactly once. stdout=%q", stdout.String())
+	}
+}
+
+func TestAssertBuiltins(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`assert(true)`, "null"},
+		{`assert(1 > 0, "math works")`, "null"},
+		{`assert(false)`, "ERROR: assertion failed"},
+		{`assert(null, "value is missing")`, "ERROR: assertion failed: value is missing"},
+		{`assert(false, "stop"); 1`, "ERROR: assertion failed: stop"},
+		{`assertEqual([1, 2], [1, 2])`, "null"},
+		{`assertEqual(1, 1.0)`, "null"},
+		{`assertEqual(len("abc"), 2)`, "ERROR: assertion failed: expected 2, got 3"},
+		{`assert()`, "ERROR: wrong number of arguments. got=0, want=1 or 2"},
+	})
+}
+
+func TestStdModules(t *testing.T) {
+	names := std.Names()
+	if len(names) == 0 {
+		t.Fatalf("no std modules found")
+	}
+
+	for _, name := range names {
+		src, ok := std.Tests(name)
+		if !ok {
+			t.Errorf("std module %s has no self-tests", name)
+			continue
+		}
+
+		var stdout bytes.Buffer
+		ctx := object.NewContext()
+		ctx.Stdout = &stdout
+		env := object.NewEnvironment()
+		env.SetContext(ctx)
+
+		l := lexer.New(string(src))
+		p := parser.New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("self-tests of std module %s did not parse: %v", name, p.Errors())
+			continue
+		}
+		if result, ok := Eval(program, env).(*object.Error); ok {
+			t.Errorf("self-tests of std module %s failed: %s", name, result.Message)
+		}
+	}
+}
+
+func TestStdImports(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`import "std/collections" as c; c.sum([1, 2, 3])`, "6"},
+		{`import "std/collections.kev" as c; c`, "module(std/collections)"},
+		{`import { capitalize } from "std/strings"; capitalize("kev")`, "Kev"},
+		{`import "std/missing" as m; m`, "ERROR: module not found: std/missing"},
+		{`import "std/strings_test" as m; m`, "ERROR: module not found: std/strings_test"},
+	})
+}
+
+func TestMathNamespace(t *testing.T) {
+	testBuiltins(t, []struct {
+		input    string
+		expected string
+	}{
+		{`math`, "module(math)"},
+		{`math.PI`, "3.141592653589793"},
+		{`math.E`, "2.718281828459045"},
+		{`math.abs(-2)`, "2"},
+		{`math.abs(-2.5)`, "2.5"},
+		{`math.abs(-9223372036854775807)`, "9223372036854775807"},
+		{`math.abs(-9223372036854775807 - 1)`, "ERROR: result of `abs` overflows INTEGER"},
+		{`math.min(3, 1, 2)`, "1"},
+		{`math.min([2.5, 4])`, "2.5"},
+		{`math.max(3, 1.0, 2)`, "3.0"},
+		{`math.max([1, 5, 3])`, "5"},
+		{`math.min([])`, "ERROR: `min` needs at least one number"},
+		{`math.max(1, "a")`, "ERROR: arguments to `max` must be INTEGER or FLOAT, got STRING"},
+		{`math.clamp(15, 0, 10)`, "10"},
+		{`math.clamp(-1, 0, 10)`, "0"},
+		{`math.clamp(5, 0.0, 10)`, "5.0"},
+		{`math.clamp(5, 10, 0)`, "ERROR: low bound of `clamp` is greater than its high bound"},
+		{`math.pow(2, 10)`, "1024"},
+		{`math.pow(3, 0)`, "1"},
+		{`math.pow(2, -1)`, "0.5"},
+		{`math.pow(2, 62)`, "4611686018427387904"},
+		{`math.pow(-2, 63)`, "-9223372036854775808"},
+		{`math.pow(2, 63)`, "ERROR: result of `pow` overflows INTEGER"},
+		{`math.pow(3, 40)`, "ERROR: result of `pow` overflows INTEGER"},
+		{`math.pow(-1, 9223372036854775807)`, "-1"},
+		{`math.pow(0, 9223372036854775807)`, "0"},
+		{`math.pow(2.0, 3)`, "8.0"},
+		{`math.sqrt(16)`, "4.0"},
+		{`math.sqrt(2.25)`, "1.5"},
+		{`math.floor(2.7)`, "2"},
+		{`math.floor(-2.5)`, "-3"},
+		{`math.ceil(2.1)`, "3"},
+		{`math.floor(5)`, "5"},
+		{`math.round(2.5)`, "3"},
+		{`math.round(-2.5)`, "-3"},
+		{`math.round(2.4)`, "2"},
+		{`math.round(3.14159, 2)`, "3.14"},
+		{`math.round(1234.5, -2)`, "1200.0"},
+		{`math.round(1.5, 400)`, "1.5"},
+		{`math.round(1.5, 9223372036854775807)`, "1.5"},
+		{`var big = math.pow(10.0, 300); math.round(big, 10) == big`, "true"},
+		{`math.round(2.5, -320)`, "0.0"},
+		{`math.round(2.5, -400)`, "0.0"},
+		{`math.round(-2.5, -9223372036854775807 - 1)`, "-0.0"},
+		{`math.round(3.14159, "2")`, "ERROR: second argument to `round` must be INTEGER, got STRING"},
+		{`math.sin(0)`, "0.0"},
+		{`math.cos(0)`, "1.0"},
+		{`math.round(math.tan(math.PI / 4), 6)`, "1.0"},
+		{`math.atan2(0, 1)`, "0.0"},
+		{`math.log(math.E)`, "1.0"},
+		{`math.log2(8)`, "3.0"},
+		{`math.log10(1000)`, "3.0"},
+		{`math.exp(0)`, "1.0"},
+		{`math.gcd(12, 18)`, "6"},
+		{`math.gcd(-4, 6)`, "2"},
+		{`math.gcd(-9223372036854775807 - 1, 0)`, "ERROR: result of `gcd` overflows INTEGER"},
+		{`math.gcd(1.5, 6)`, "ERROR: arguments to `gcd` must be INTEGER, got FLOAT"},
+		{`math.sqrt("4")`, "ERROR: arguments to `sqrt` must be INTEGER or FLOAT, got STRING"},
+		{`math.nope`, "ERROR: module math has no export nope"},
+		{`var math = 1; math`, "1"},
+	})
+}
+
+func TestJSONNamespace(t *testing.T) {
+	tests := []struct {
+		src      string // bound to src, since kev strings can't hold quotes
+		input    string
+		expected string
+	}{
+		{`{"b": 1, "a": [true, null, 2.5, "x"]}`, `json.parse(src)`, "{b: 1, a: [true, null, 2.5, x]}"},
+		{`{"a": {"b": [1, 2]}}`, `json.parse(src).a.b[1]`, "2"},
+		{``, `json.parse("[1, 2.5, null]")`, "[1, 2.5, null]"},
+		{`1e3`, `json.parse(src)`, "1000.0"},
+		{`1.0`, `type(json.parse(src))`, "FLOAT"},
+		{`-12`, `type(json.parse(src))`, "INTEGER"},
+		{`"héllo"`, `json.parse(src)`, "héllo"},
+		{`  [ ]  `, `json.parse(src)`, "[]"},
+		{`{"a": 1`, `json.parse(src)`, "ERROR: could not parse JSON: unexpected end of JSON input"},
+		{`[1] [2]`, `json.parse(src)`, "ERROR: could not parse JSON: unexpected data after the JSON value"},
+		{`{"a": }`, `json.parse(src)`, "ERROR: could not parse JSON: missing value after object key"},
+		{``, `json.parse(src)`, "ERROR: could not parse JSON: unexpected EOF"},
+		{`{"b": 1, "a": [true, null, 2.5, "x<y"]}`, `json.stringify(json.parse(src)) == src`, "false"},
+		{`{"b":1,"a":[true,null,2.5,"x<y"]}`, `json.stringify(json.parse(src)) == src`, "true"},
+		{``, `json.stringify({"a": [1, 2], "b": {}})`, `{"a":[1,2],"b":{}}`},
+		{``, `json.stringify("tab	quote")`, `"tab\tquote"`},
+		{``, `json.stringify([1.0, 0.5, null, false])`, `[1.0,0.5,null,false]`},
+		{``, `json.stringify({"a": [1, 2], "b": {}}, 2)`, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}"},
+		{``, `json.stringify([1], "	")`, "[\n\t1\n]"},
+		{``, `json.stringify({1: "a"})`, "ERROR: could not stringify: hash key 1 is INTEGER, JSON keys must be STRING"},
+		{``, `json.stringify({"f": func(x) { x }})`, "ERROR: could not stringify: FUNCTION can't be represented in JSON"},
+		{``, `json.stringify([len])`, "ERROR: could not stringify: BUILTIN can't be represented in JSON"},
+		{``, `json.stringify(1, -1)`, "ERROR: indent for `stringify` must not be negative, got -1"},
+		{``, `json.stringify([1], 10)`, "[\n          1\n]"},
+		{``, `json.stringify(1, 11)`, "ERROR: indent for `stringify` must be at most 10, got 11"},
+		{``, `json.stringify(1, 9223372036854775807)`, "ERROR: indent for `stringify` must be at most 10, got 9223372036854775807"},
+		{``, `json.stringify(1, true)`, "ERROR: second argument to `stringify` must be INTEGER or STRING, got BOOLEAN"},
+		{``, `json.parse(1)`, "ERROR: arguments to `parse` must be STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("src", &object.String{Value: tt.src})
+
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		evaluated := Eval(p.ParseProgram(), env)
+
+		got := evaluated.Inspect()
+		if errObj, ok := evaluated.(*object.Error); ok {
+			got = "ERROR: " + errObj.Message
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q with src=%q. got=%q, want=%q", tt.input, tt.src, got, tt.expected)
+		}
+	}
+}
+
+func TestFileBuiltins(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"data/a.txt": "hello",
+		"data/b.txt": "",
+		"lib.kev":    `export var name = "lib";`,
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`listDir()`, "[data, lib.kev]"},
+		{`readFile("data/a.txt")`, "hello"},
+		{`readFile("/data/a.txt")`, "hello"},
+		{`writeFile("out.txt", "one"); appendFile("out.txt", " two"); readFile("out.txt")`, "one two"},
+		{`appendFile("new.txt", "x"); readFile("new.txt")`, "x"},
+		{`listDir("data")`, "[a.txt, b.txt]"},
+		{`[exists("data/a.txt"), exists("data/c.txt"), isDir("data"), isDir("data/a.txt")]`, "[true, false, true, false]"},
+		{`writeFile("gone.txt", ""); remove("gone.txt"); exists("gone.txt")`, "false"},
+		{`import "lib.kev" as lib; lib.name`, "lib"},
+		{`readFile("data/c.txt")`, "ERROR: could not read file: open " + filepath.Join(dir, "data/c.txt") + ": no such file or directory"},
+		{`readFile("../secret.txt")`, "ERROR: could not read file: read ../secret.txt: path is outside of the root directory"},
+		{`writeFile("data/../../x.txt", "x")`, "ERROR: could not write file: write ../x.txt: path is outside of the root directory"},
+		{`readFile(1)`, "ERROR: arguments to `readFile` must be STRING, got INTEGER"},
+		{`writeFile("x.txt")`, "ERROR: wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		ctx := object.NewContext()
+		ctx.FS = object.RootedFileSystem(dir)
+		env := object.NewEnvironment()
+		env.SetContext(ctx)
+
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		evaluated := Eval(p.ParseProgram(), env)
+
+		got := evaluated.Inspect()
+		if errObj, ok := evaluated.(*object.Error); ok {
+			got = "ERROR: " + errObj.Message
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSandboxedFileSystems(t *testing.T) {
+	dir := writeModules(t, map[string]string{"a.txt": "hello"})
+
+	tests := []struct {
+		fsys     object.FileSystem
+		input    string
+		expected string
+	}{
+		{object.ReadOnlyFileSystem(object.RootedFileSystem(dir)), `readFile("a.txt")`, "hello"},
+		{object.ReadOnlyFileSystem(object.RootedFileSystem(dir)), `writeFile("a.txt", "x")`, "ERROR: could not write file: write a.txt: file system is read-only"},
+		{object.ReadOnlyFileSystem(object.RootedFileSystem(dir)), `remove("a.txt")`, "ERROR: could not remove file: remove a.txt: file system is read-only"},
+		{object.DenyFileSystem(), `readFile("a.txt")`, "ERROR: could not read file: read a.txt: permission denied"},
+		{object.DenyFileSystem(), `exists("a.txt")`, "ERROR: could not check file: stat a.txt: permission denied"},
+		{object.DenyFileSystem(), `import "a.kev" as a`, "ERROR: could not import a.kev: read a.kev: permission denied"},
+		{nil, `listDir()`, "ERROR: could not list directory: readdir .: permission denied"},
+		{object.FromFS(fstest.MapFS{"lib/x.kev": {Data: []byte(`import "y.kev" as y; export var v = y.v;`)}, "lib/y.kev": {Data: []byte(`export var v = 7;`)}}), `import "/lib/x.kev" as x; x.v`, "7"},
+		{object.FromFS(fstest.MapFS{"a/b.txt": {}}), `[listDir("a"), exists("./a/b.txt"), exists("b.txt")]`, "[[b.txt], true, false]"},
+		{object.FromFS(fstest.MapFS{}), `appendFile("a.txt", "x")`, "ERROR: could not append to file: append a.txt: file system is read-only"},
+	}
+
+	for _, tt := range tests {
+		ctx := object.NewContext()
+		ctx.FS = tt.fsys
+		env := object.NewEnvironment()
+		env.SetContext(ctx)
+
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		evaluated := Eval(p.ParseProgram(), env)
+
+		got := "null"
+		if evaluated != nil {
+			got = evaluated.Inspect()
+		}
+		if errObj, ok := evaluated.(*object.Error); ok {
+			got = "ERROR: " + errObj.Message
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestPathNamespace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`path.join("a", "b", "../c")`, "a/c"},
+		{`path.join()`, ""},
+		{`path.base("src/main.kev")`, "main.kev"},
+		{`path.dir("src/main.kev")`, "src"},
+		{`path.ext("src/main.kev")`, ".kev"},
+		{`path.ext("README")`, ""},
+		{`path.clean("a/./b/../c/")`, "a/c"},
+		{`[path.isAbs("/tmp"), path.isAbs("tmp")]`, "[true, false]"},
+		{`path.join("a", 1)`, "ERROR: arguments to `join` must be STRING, got INTEGER"},
+		{`var path = "x"; path`, "x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		got := evaluated.Inspect()
+		if errObj, ok := evaluated.(*object.Error); ok {
+			got = "ERROR: " + errObj.Message
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestRegexNamespace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`re.compile("[0-9]+")`, "regex([0-9]+)"},
+		{`type(re.compile("a"))`, "REGEX"},
+		{`re.compile("a(b")`, "ERROR: invalid regular expression: missing closing ): `a(b`"},
+		{`re.compile("a") == re.compile("a")`, "true"},
+		{`re.compile("a") == re.compile("b")`, "false"},
+		{`{re.compile("a"): 1}[re.compile("a")]`, "1"},
+		{`var r = re.compile("(?P<key>[a-z]+)=([0-9]+)"); [r.pattern, r.names]`, "[(?P<key>[a-z]+)=([0-9]+), [key, ]]"},
+		{`re.compile("a").flags`, "ERROR: regex has no property flags"},
+		{`re.match("^[a-z]+$", "kev")`, "true"},
+		{`re.match("^[a-z]+$", "kev1")`, "false"},
+		{`var digits = re.compile("\d+"); re.match(digits, "a1")`, "true"},
+		{`re.find("([a-z]+)=([0-9]+)", "x a=1 b=2")`, "[a=1, a, 1]"},
+		{`re.find("a(x)?", "ba")`, "[a, null]"},
+		{`re.find("[0-9]", "abc")`, "null"},
+		{`re.findAll("([a-z])([0-9])", "a1 b2 c3")`, "[[a1, a, 1], [b2, b, 2], [c3, c, 3]]"},
+		{`re.findAll("[0-9]", "a1b2c3", 2)`, "[[1], [2]]"},
+		{`re.findAll("[0-9]", "abc")`, "[]"},
+		{`re.groups("(?P<key>[a-z]+)=(?P<value>[0-9]+)", "a=1")`, "{key: a, value: 1}"},
+		{`re.groups("(?P<key>[a-z]+)", "1")`, "null"},
+		{`re.replace("[0-9]+", "a1b22", "#")`, "a#b#"},
+		{`re.replace("([a-z])=([0-9])", "a=1 b=2", "$2=$1")`, "1=a 2=b"},
+		{`re.replace("[0-9]+", "a1b22", func(m) { len(m) })`, "a1b2"},
+		{`re.replace("([a-z])([0-9])", "a1 b2", func(m, l, d) { upper(l) + d + d })`, "A11 B22"},
+		{`re.replace("[a-z]", "ab", upper)`, "AB"},
+		{`re.replace("[a-z]", "ab", func(m) { missing })`, "ERROR: identifier not found: missing"},
+		{`re.replace("[a-z]", "ab", 1)`, "ERROR: third argument to `replace` must be STRING or FUNCTION, got INTEGER"},
+		{`re.split(" *, *", "a , b,c")`, "[a, b, c]"},
+		{`re.split(",", "a,b,c", 2)`, "[a, b,c]"},
+		{`re.escape("1.5+x")`, `1\.5\+x`},
+		{`re.match(re.escape("1.5"), "105")`, "false"},
+		{`re.match(1, "a")`, "ERROR: first argument to `match` must be REGEX or STRING, got INTEGER"},
+		{`re.match("a", 1)`, "ERROR: second argument to `match` must be STRING, got INTEGER"},
+		{`re.find("a")`, "ERROR: wrong number of arguments. got=1, want=2"},
+		{`re.split("a")`, "ERROR: wrong number of arguments. got=1, want=2 or 3"},
+		{`re.findAll("a", "a", "1")`, "ERROR: third argument to `findAll` must be INTEGER, got STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		got := evaluated.Inspect()
+		if errObj, ok := evaluated.(*object.Error); ok {
+			got = "ERROR: " + errObj.Message
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestTimeNamespace(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.now()`, "2024-05-01T12:30:00Z"},
+		{`type(time.now())`, "TIME"},
+		{`var t = time.now(); [t.year, t.month, t.day, t.hour, t.minute, t.second, t.weekday, t.yearDay, t.zone]`, "[2024, 5, 1, 12, 30, 0, 3, 122, UTC]"},
+		{`[time.now().unix, time.now().unixMilli]`, "[1714566600, 1714566600000]"},
+		{`time.now().century`, "ERROR: time has no property century"},
+		{`time.unix(0)`, "1970-01-01T00:00:00Z"},
+		{`time.unix(1.5)`, "1970-01-01T00:00:01.5Z"},
+		{`time.unixMilli(1714566600250)`, "2024-05-01T12:30:00.25Z"},
+		{`time.date(2024, 2, 30)`, "2024-03-01T00:00:00Z"},
+		{`time.date(2024, 5, 1, 14, 30, 0, "Europe/Paris")`, "2024-05-01T14:30:00+02:00"},
+		{`time.date(2024, 5, 1, 14, 30, 0, "Europe/Paris") == time.now()`, "true"},
+		{`time.date(2024, 5, 1, "Mars/Base")`, "ERROR: unknown time zone Mars/Base"},
+		{`time.date(2024, 5)`, "ERROR: wrong number of arguments. got=2, want=3 to 6 numbers and an optional zone"},
+		{`time.date(2024, 5, 1.5)`, "ERROR: arguments to `date` must be INTEGER, got FLOAT"},
+		{`time.date(9999, 12, 31, 23, 59, 59)`, "9999-12-31T23:59:59Z"},
+		{`time.date(2024, 1, 1, 0, 0, 100000000)`, "2027-03-03T09:46:40Z"},
+		{`time.date(9223372036854775807, 1, 1)`, "ERROR: arguments to `date` must be between -100000000 and 100000000, got 9223372036854775807"},
+		{`time.date(2024, 1, -9223372036854775807)`, "ERROR: arguments to `date` must be between -100000000 and 100000000, got -9223372036854775807"},
+		{`time.date(10000, 1, 1)`, "ERROR: year of the time built by `date` must be between 0 and 9999, got 10000"},
+		{`time.date(9999, 12, 32)`, "ERROR: year of the time built by `date` must be between 0 and 9999, got 10000"},
+		{`time.format(time.now())`, "2024-05-01T12:30:00Z"},
+		{`time.format(time.now(), "Mon Jan 2 2006 3:04PM")`, "Wed May 1 2024 12:30PM"},
+		{`time.format(time.now(), time.DATE_ONLY)`, "2024-05-01"},
+		{`time.format("now")`, "ERROR: first argument to `format` must be TIME, got STRING"},
+		{`time.parse("2024-05-01T10:00:00+02:00")`, "2024-05-01T10:00:00+02:00"},
+		{`time.parse("2024-05-01", time.DATE_ONLY)`, "2024-05-01T00:00:00Z"},
+		{`time.parse("2024-05-01 09:00:00", time.DATE_TIME, "America/New_York")`, "2024-05-01T09:00:00-04:00"},
+		{`time.parse("May 1", time.DATE_ONLY)`, `ERROR: could not parse time: parsing time "May 1" as "2006-01-02": cannot parse "May 1" as "2006"`},
+		{`time.duration("1h30m")`, "1h30m0s"},
+		{`time.duration("soon")`, `ERROR: could not parse duration: time: invalid duration "soon"`},
+		{`time.HOUR * 2 + time.MINUTE * 15`, "2h15m0s"},
+		{`time.HOUR / 4`, "15m0s"},
+		{`time.HOUR * 1.5`, "1h30m0s"},
+		{`2 * time.HOUR + 15 * time.MINUTE`, "2h15m0s"},
+		{`0.5 * time.HOUR`, "30m0s"},
+		{`2 / time.HOUR`, "ERROR: type mismatch: INTEGER / DURATION"},
+		{`time.HOUR / time.MINUTE`, "60.0"},
+		{`time.HOUR / 0`, "ERROR: division by zero"},
+		{`time.HOUR - time.duration("90m")`, "-30m0s"},
+		{`time.HOUR > time.MINUTE`, "true"},
+		{`var d = time.duration("1h30m"); [d.hours, d.minutes, d.seconds, d.milliseconds, d.nanoseconds]`, "[1.5, 90.0, 5400.0, 5.4e+06, 5400000000000]"},
+		{`time.now() + time.HOUR`, "2024-05-01T13:30:00Z"},
+		{`time.HOUR + time.now()`, "2024-05-01T13:30:00Z"},
+		{`time.now() - time.duration("24h")`, "2024-04-30T12:30:00Z"},
+		{`time.now() - time.date(2024, 1, 1)`, "2916h30m0s"},
+		{`time.now() > time.date(2024, 1, 1)`, "true"},
+		{`time.now() + 1`, "ERROR: type mismatch: TIME + INTEGER"},
+		{`time.since(time.date(2024, 5, 1))`, "12h30m0s"},
+		{`time.until(time.date(2024, 5, 2))`, "11h30m0s"},
+		{`time.addDate(time.date(2024, 1, 31), 0, 1, 0)`, "2024-03-02T00:00:00Z"},
+		{`time.addDate(time.now(), 1, 0, -1)`, "2025-04-30T12:30:00Z"},
+		{`time.addDate(time.unix(0), 9223372036854775807, 0, 0)`, "ERROR: years, months and days passed to `addDate` must be between -100000000 and 100000000, got 9223372036854775807"},
+		{`time.addDate(time.unix(0), 0, 100000000, 0)`, "ERROR: year of the time built by `addDate` must be between 0 and 9999, got 8335303"},
+		{`time.addDate(time.unix(0), -1971, 0, 0)`, "ERROR: year of the time built by `addDate` must be between 0 and 9999, got -1"},
+		{`time.inZone(time.now(), "Asia/Tokyo")`, "2024-05-01T21:30:00+09:00"},
+		{`time.inZone(time.now(), "Asia/Tokyo").zone`, "Asia/Tokyo"},
+		{`time.utc(time.inZone(time.now(), "Asia/Tokyo"))`, "2024-05-01T12:30:00Z"},
+		{`{time.now(): "now"}[time.inZone(time.now(), "Asia/Tokyo")]`, "now"},
+		{`time.utc(1)`, "ERROR: argument to `utc` must be TIME, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		ctx := object.NewContext()
+		ctx.Clock = func() time.Time { return now }
+		env := object.NewEnvironment()
+		env.SetContext(ctx)
+
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		evaluated := Eval(p.ParseProgram(), env)
+
+		got := evaluated.Inspect()
+		if errObj, ok := evaluated.(*object.Error); ok {
+			got = "ERROR: " + errObj.Message
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestProcessBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`args`, "[a, b c]"},
+		{`len(args)`, "2"},
+		{`var args = 1; args`, "1"},
+		{`func(args) { args }(5)`, "5"},
+		{`env("HOME")`, "/home/kev"},
+		{`env("EMPTY")`, ""},
+		{`env("MISSING")`, "null"},
+		{`env("MISSING", "8080")`, "8080"},
+		{`env("HOME", "x")`, "/home/kev"},
+		{`env(1)`, "ERROR: first argument to `env` must be STRING, got INTEGER"},
+		{`exit()`, "ERROR: exit status 0"},
+		{`exit(3); print("unreachable")`, "ERROR: exit status 3"},
+		{`var f = func() { exit(2); 1 }; f() + 1`, "ERROR: exit status 2"},
+		{`map([1, 2], func(x) { if (x == 2) { exit(4) }; x })`, "ERROR: exit status 4"},
+		{`exit(255)`, "ERROR: exit status 255"},
+		{`exit(256)`, "ERROR: status for `exit` must be between 0 and 255, got 256"},
+		{`exit(-1)`, "ERROR: status for `exit` must be between 0 and 255, got -1"},
+		{`exit("1")`, "ERROR: argument to `exit` must be INTEGER, got STRING"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		ctx := object.NewContext()
+		ctx.Stdout = &out
+		ctx.Args = []string{"a", "b c"}
+		ctx.LookupEnv = func(name string) (string, bool) {
+			value, ok := map[string]string{"HOME": "/home/kev", "EMPTY": ""}[name]
+			return value, ok
+		}
+		env := object.NewEnvironment()
+		env.SetContext(ctx)
+
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		evaluated := Eval(p.ParseProgram(), env)
+
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+		if out.Len() != 0 {
+			t.Errorf("%q wrote %q after exiting", tt.input, out.String())
+		}
+	}
+
+	exited := testEval(`exit(5)`).(*object.Error)
+	if !exited.Exit || exited.Code != 5 {
+		t.Errorf("exit returned the wrong error. got=%+v", exited)
+	}
+	if failed := testEval(`missing`).(*object.Error); failed.Exit {
+		t.Errorf("error was marked as an exit. got=%+v", failed)
+	}
+
+	ctx := object.NewContext()
+	ctx.LookupEnv = nil
+	env := object.NewEnvironment()
+	env.SetContext(ctx)
+	if evaluated := Eval(parser.New(lexer.New(`[args, env("PATH")]`)).ParseProgram(), env); evaluated.Inspect() != "[[], null]" {
+		t.Errorf("context without args or env returned %s", evaluated.Inspect())
+	}
+}
@@ -0,0 +1,174 @@
+package evaluator
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gage-McGuire/kev/ast"
+	"github.com/Gage-McGuire/kev/lexer"
+	"github.com/Gage-McGuire/kev/object"
+	"github.com/Gage-McGuire/kev/parser"
+	"github.com/Gage-McGuire/kev/std"
+)
+
+// evaluates the import statement by importing the module
+// and binding either the module itself or the exports
+// listed in the statement in the environment
+func evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
+	imported := importModule(node.Path, env)
+	if isError(imported) {
+		return imported
+	}
+	module := imported.(*object.Module)
+
+	if node.Alias != nil {
+		env.Set(node.Alias.Value, module)
+		return nil
+	}
+
+	for _, binding := range node.Bindings {
+		obj, ok := module.Get(binding.Name.Value)
+		if !ok {
+			return newError("module %s has no export %s", module.Name, binding.Name.Value)
+		}
+		env.Set(binding.Alias.Value, obj)
+	}
+	return nil
+}
+
+// importModule returns the module at the path, which is resolved
+// relative to the file the importing code came from. Paths starting
+// with std/ refer to the std modules embedded in the binary instead.
+// Each module is evaluated once per context in its own environment,
+// and importing it again returns the same module. A module that ends
+// up importing itself, directly or through other modules, is an error
+func importModule(path string, env *object.Environment) object.Object {
+	ctx := env.Context()
+
+	var resolved string
+	name, isStd := stdModuleName(path)
+	if isStd {
+		resolved = stdPrefix + name
+	} else {
+		resolved = resolveModulePath(path, env.File())
+	}
+
+	if module, ok := ctx.Module(resolved); ok {
+		return module
+	}
+
+	if cycle, ok := ctx.StartImport(resolved); !ok {
+		names := make([]string, len(cycle))
+		for idx, p := range cycle {
+			names[idx] = filepath.Base(p)
+		}
+		return newError("import cycle: %s", strings.Join(names, " -> "))
+	}
+	defer ctx.FinishImport()
+
+	src, err := readModule(ctx.FileSystem(), resolved, isStd)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newError("module not found: %s", path)
+	}
+	if err != nil {
+		return newError("could not import %s: %s", path, err)
+	}
+
+	l := lexer.New(string(src))
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return newError("could not parse module %s: %s", path, strings.Join(p.Errors(), "; "))
+	}
+
+	moduleEnv := object.NewEnvironment()
+	moduleEnv.SetContext(ctx)
+	moduleEnv.SetFile(resolved)
+	if result := Eval(program, moduleEnv); isError(result) {
+		return result
+	}
+
+	moduleName := filepath.Base(resolved)
+	if isStd {
+		moduleName = resolved
+	}
+	module := &object.Module{
+		Name:    moduleName,
+		Path:    resolved,
+		Exports: make(map[string]object.Object),
+	}
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			name := export.Statement.Name.Value
+			module.Exports[name], _ = moduleEnv.Get(name)
+		}
+	}
+	ctx.SetModule(resolved, module)
+	return module
+}
+
+// stdPrefix starts the path of every std module
+const stdPrefix = "std/"
+
+// stdModuleName returns the name of the std module the import
+// path refers to, e.g. "strings" for "std/strings" or "std/strings.kev"
+func stdModuleName(path string) (string, bool) {
+	if !strings.HasPrefix(path, stdPrefix) {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(path, stdPrefix), ".kev"), true
+}
+
+// readModule returns the source of the module, which is embedded
+// in the binary for std modules and read from the file system otherwise
+func readModule(fsys object.FileSystem, path string, isStd bool) ([]byte, error) {
+	if !isStd {
+		return fsys.ReadFile(path)
+	}
+	src, ok := std.Source(strings.TrimPrefix(path, stdPrefix))
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return src, nil
+}
+
+// resolveModulePath turns the path of an import into the path of
+// the module on the file system. Relative paths are resolved against
+// the directory of the importing file, or the working directory
+// when the importing code didn't come from a file. The path is left
+// relative when the importer's path is, since file systems like a
+// rooted one resolve relative paths their own way
+func resolveModulePath(path, importer string) string {
+	if !filepath.IsAbs(path) {
+		dir := "."
+		if importer != "" {
+			dir = filepath.Dir(importer)
+		}
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// evaluates the member expression, e.g. lib.name. Modules return
+// their export with the name, hashes return the value of the string
+// key with the name, and objects that implement object.Indexable
+// are indexed with the name
+func evalMemberExpression(left object.Object, name string) object.Object {
+	switch left := left.(type) {
+	case *object.Module:
+		obj, ok := left.Get(name)
+		if !ok {
+			return newError("module %s has no export %s", left.Name, name)
+		}
+		return obj
+	case *object.Hash:
+		return evalHashIndexExpression(left, &object.String{Value: name})
+	default:
+		if isIndexable(left) {
+			return left.(object.Indexable).Index(&object.String{Value: name})
+		}
+		return newError("member access not supported: %s", left.Type())
+	}
+}
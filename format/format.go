@@ -0,0 +1,411 @@
+package format
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Gage-McGuire/kev/ast"
+	"github.com/Gage-McGuire/kev/lexer"
+	"github.com/Gage-McGuire/kev/parser"
+	"github.com/Gage-McGuire/kev/token"
+)
+
+// Source formats the kev source in the canonical style used by
+// kev fmt and returns the result. It returns an error holding every
+// parsing error, one per line, when the source can't be parsed.
+//
+// kev has no comments, so formatting the parsed program loses
+// nothing but the blank lines and redundant parentheses the source
+// had. Blank lines are put between the imports, the declarations and
+// the other top-level statements, and around top-level statements
+// that span more than one line instead
+func Source(src []byte) ([]byte, error) {
+	l := lexer.New(string(src))
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+
+	out := Program(program)
+
+	// keep the shebang line, which the lexer skips
+	if strings.HasPrefix(string(src), "#!") {
+		shebang, _, _ := strings.Cut(string(src), "\n")
+		out = strings.TrimRight(shebang, "\r") + "\n" + out
+	}
+	return []byte(out), nil
+}
+
+// Program returns the formatted source of the program
+func Program(program *ast.Program) string {
+	var out strings.Builder
+	var previous string
+	for idx, stmt := range program.Statements {
+		formatted := statement(stmt, 0)
+		if idx > 0 {
+			kind, previousKind := statementKind(stmt), statementKind(program.Statements[idx-1])
+			if kind != previousKind || isMultiline(previous) || isMultiline(formatted) {
+				out.WriteString("\n")
+			}
+		}
+		out.WriteString(formatted)
+		out.WriteString(terminator(program.Statements, idx, true))
+		out.WriteString("\n")
+		previous = formatted
+	}
+	return out.String()
+}
+
+// statementKind groups the top-level statements into imports,
+// declarations and everything else, which are set apart by blank lines
+func statementKind(stmt ast.Statement) string {
+	switch stmt.(type) {
+	case *ast.ImportStatement:
+		return "import"
+	case *ast.VarStatement, *ast.ExportStatement:
+		return "declaration"
+	default:
+		return "other"
+	}
+}
+
+// inlineWidth is the longest a statement can be
+// for the block holding it to fit on one line
+const inlineWidth = 60
+
+// the precedences of the expressions, matching the parser's,
+// which decide where parentheses are needed
+const (
+	_ int = iota
+	lowest
+	nullish
+	equals
+	lessGreater
+	sum
+	product
+	prefix
+	postfix
+	primary
+)
+
+// precedence returns the precedence of the expression. Literals,
+// identifiers, if expressions and function literals never need
+// parentheses, since the parser reads them before any operator
+func precedence(exp ast.Expression) int {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		switch exp.Operator {
+		case "??":
+			return nullish
+		case "==", "!=":
+			return equals
+		case "<", ">":
+			return lessGreater
+		case "+", "-":
+			return sum
+		default:
+			return product
+		}
+	case *ast.PrefixExpression:
+		return prefix
+	case *ast.CallExpression, *ast.IndexExpression, *ast.SliceExpression, *ast.MemberExpression:
+		return postfix
+	default:
+		return primary
+	}
+}
+
+// statement returns the formatted statement, without the
+// semicolon ending it, indented for the given depth
+func statement(stmt ast.Statement, depth int) string {
+	switch stmt := stmt.(type) {
+	case *ast.VarStatement:
+		return "var " + stmt.Name.Value + " = " + expression(stmt.Value, depth)
+	case *ast.ExportStatement:
+		// exported functions are the API of a module,
+		// so their bodies always get lines of their own
+		if fn, ok := stmt.Statement.Value.(*ast.FunctionLiteral); ok {
+			return "export var " + stmt.Statement.Name.Value + " = " + functionLiteral(fn, depth, true)
+		}
+		return "export " + statement(stmt.Statement, depth)
+	case *ast.ReturnStatement:
+		return "return " + expression(stmt.ReturnValue, depth)
+	case *ast.ImportStatement:
+		return importStatement(stmt)
+	case *ast.ExpressionStatement:
+		return expression(stmt.Expression, depth)
+	default:
+		return stmt.String()
+	}
+}
+
+// importStatement returns the formatted import statement
+func importStatement(stmt *ast.ImportStatement) string {
+	path := `"` + stmt.Path + `"`
+	if stmt.Alias != nil {
+		return "import " + path + " as " + stmt.Alias.Value
+	}
+	bindings := make([]string, len(stmt.Bindings))
+	for idx, b := range stmt.Bindings {
+		bindings[idx] = b.Name.Value
+		if b.Alias.Value != b.Name.Value {
+			bindings[idx] += " as " + b.Alias.Value
+		}
+	}
+	return "import { " + strings.Join(bindings, ", ") + " } from " + path
+}
+
+// terminator returns the semicolon ending the statement at idx,
+// if it needs one. The last expression of a block is its value and
+// is left without one, and so are if expressions, unless the next
+// statement starts with a token that would continue the expression
+func terminator(stmts []ast.Statement, idx int, topLevel bool) string {
+	exp, ok := stmts[idx].(*ast.ExpressionStatement)
+	if !ok {
+		return ";"
+	}
+	if _, isIf := exp.Expression.(*ast.IfExpression); isIf {
+		if idx+1 < len(stmts) && continuesExpression(stmts[idx+1]) {
+			return ";"
+		}
+		return ""
+	}
+	if !topLevel && idx == len(stmts)-1 {
+		return ""
+	}
+	return ";"
+}
+
+// continuesExpression returns whether the statement starts with a
+// token the parser would read as part of the expression before it
+func continuesExpression(stmt ast.Statement) bool {
+	exp, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	switch firstToken(exp.Expression) {
+	case token.LPAREN, token.LBRACKET, token.MINUS:
+		return true
+	default:
+		return false
+	}
+}
+
+// firstToken returns the type of the first token of the
+// expression once it is formatted, e.g. token.MINUS for -x
+func firstToken(exp ast.Expression) token.TokenType {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		return leftFirstToken(exp.Left, precedence(exp))
+	case *ast.CallExpression:
+		return leftFirstToken(exp.Function, postfix)
+	case *ast.IndexExpression:
+		return leftFirstToken(exp.Left, postfix)
+	case *ast.SliceExpression:
+		return leftFirstToken(exp.Left, postfix)
+	case *ast.MemberExpression:
+		return leftFirstToken(exp.Object, postfix)
+	case *ast.PrefixExpression:
+		return exp.Token.Type
+	case *ast.ArrayLiteral:
+		return token.LBRACKET
+	default:
+		return token.IDENT
+	}
+}
+
+// leftFirstToken returns the first token of the left side of an
+// expression, which is a parenthesis when it binds looser than min
+func leftFirstToken(left ast.Expression, min int) token.TokenType {
+	if precedence(left) < min {
+		return token.LPAREN
+	}
+	return firstToken(left)
+}
+
+// expression returns the formatted expression,
+// with nested blocks indented for the given depth
+func expression(exp ast.Expression, depth int) string {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		return exp.Value
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.Boolean, *ast.NullLiteral:
+		return exp.TokenLiteral()
+	case *ast.StringLiteral:
+		return `"` + exp.Value + `"`
+	case *ast.PrefixExpression:
+		return exp.Operator + operand(exp.Right, prefix, depth)
+	case *ast.InfixExpression:
+		p := precedence(exp)
+		left := operand(exp.Left, p, depth)
+		right := operand(exp.Right, p+1, depth)
+		return left + " " + exp.Operator + " " + right
+	case *ast.IfExpression:
+		return ifExpression(exp, depth)
+	case *ast.FunctionLiteral:
+		return functionLiteral(exp, depth, false)
+	case *ast.ArrayLiteral:
+		return arrayLiteral(exp, depth)
+	case *ast.HashLiteral:
+		return hashLiteral(exp, depth)
+	case *ast.CallExpression:
+		args := make([]string, len(exp.Arguments))
+		for idx, arg := range exp.Arguments {
+			args[idx] = expression(arg, depth)
+		}
+		return operand(exp.Function, postfix, depth) + optional(exp.Optional) + "(" + strings.Join(args, ", ") + ")"
+	case *ast.IndexExpression:
+		return operand(exp.Left, postfix, depth) + optional(exp.Optional) + "[" + expression(exp.Index, depth) + "]"
+	case *ast.SliceExpression:
+		var start, end string
+		if exp.Start != nil {
+			start = expression(exp.Start, depth)
+		}
+		if exp.End != nil {
+			end = expression(exp.End, depth)
+		}
+		return operand(exp.Left, postfix, depth) + optional(exp.Optional) + "[" + start + ":" + end + "]"
+	case *ast.MemberExpression:
+		dot := "."
+		if exp.Optional {
+			dot = "?."
+		}
+		return operand(exp.Object, postfix, depth) + dot + exp.Property.Value
+	default:
+		return exp.String()
+	}
+}
+
+// operand returns the formatted expression, wrapped in parentheses
+// when it binds looser than the given precedence
+func operand(exp ast.Expression, min int, depth int) string {
+	formatted := expression(exp, depth)
+	if precedence(exp) < min {
+		return "(" + formatted + ")"
+	}
+	return formatted
+}
+
+// optional returns the ?. written before the
+// brackets or parentheses of optional chains
+func optional(isOptional bool) string {
+	if isOptional {
+		return "?."
+	}
+	return ""
+}
+
+// ifExpression returns the formatted if expression. Both blocks are
+// kept on one line when they fit, e.g. if (x) { 1 } else { 2 }
+func ifExpression(exp *ast.IfExpression, depth int) string {
+	inline := canInline(exp.Consequence, depth) && (exp.Alternative == nil || canInline(exp.Alternative, depth))
+	out := "if (" + expression(exp.Condition, depth) + ") " + block(exp.Consequence, depth, inline)
+	if exp.Alternative != nil {
+		out += " else " + block(exp.Alternative, depth, inline)
+	}
+	return out
+}
+
+// functionLiteral returns the formatted function literal.
+// Its body is kept on one line when it fits, unless multiline is set
+func functionLiteral(fn *ast.FunctionLiteral, depth int, multiline bool) string {
+	params := make([]string, len(fn.Parameters))
+	for idx, param := range fn.Parameters {
+		params[idx] = param.Value
+	}
+	inline := !multiline && canInline(fn.Body, depth)
+	if multiline && len(fn.Body.Statements) == 0 {
+		inline = true
+	}
+	return "func(" + strings.Join(params, ", ") + ") " + block(fn.Body, depth, inline)
+}
+
+// canInline returns whether the block is empty or
+// holds a single statement short enough to fit on one line
+func canInline(b *ast.BlockStatement, depth int) bool {
+	switch len(b.Statements) {
+	case 0:
+		return true
+	case 1:
+		formatted := statement(b.Statements[0], depth+1)
+		return !isMultiline(formatted) && len(formatted) <= inlineWidth
+	default:
+		return false
+	}
+}
+
+// block returns the formatted block, either on one line,
+// e.g. { x + 1 }, or with each statement on its own line
+func block(b *ast.BlockStatement, depth int, inline bool) string {
+	if len(b.Statements) == 0 {
+		return "{}"
+	}
+	if inline {
+		return "{ " + statement(b.Statements[0], depth+1) + terminator(b.Statements, 0, false) + " }"
+	}
+
+	var out strings.Builder
+	out.WriteString("{\n")
+	for idx, stmt := range b.Statements {
+		out.WriteString(indent(depth + 1))
+		out.WriteString(statement(stmt, depth+1))
+		out.WriteString(terminator(b.Statements, idx, false))
+		out.WriteString("\n")
+	}
+	out.WriteString(indent(depth) + "}")
+	return out.String()
+}
+
+// arrayLiteral returns the formatted array literal. The elements
+// go on their own lines when any of them spans more than one line
+func arrayLiteral(arr *ast.ArrayLiteral, depth int) string {
+	elements := make([]string, len(arr.Elements))
+	multiline := false
+	for idx, el := range arr.Elements {
+		elements[idx] = expression(el, depth)
+		multiline = multiline || isMultiline(elements[idx])
+	}
+	if !multiline {
+		return "[" + strings.Join(elements, ", ") + "]"
+	}
+
+	for idx := range elements {
+		elements[idx] = indent(depth+1) + expression(arr.Elements[idx], depth+1)
+	}
+	return "[\n" + strings.Join(elements, ",\n") + "\n" + indent(depth) + "]"
+}
+
+// hashLiteral returns the formatted hash literal. The pairs
+// go on their own lines when any of them spans more than one line
+func hashLiteral(hash *ast.HashLiteral, depth int) string {
+	pairs := make([]string, len(hash.Keys))
+	multiline := false
+	for idx, key := range hash.Keys {
+		pairs[idx] = expression(key, depth) + ": " + expression(hash.Pairs[key], depth)
+		multiline = multiline || isMultiline(pairs[idx])
+	}
+	if !multiline {
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+
+	var out strings.Builder
+	out.WriteString("{\n")
+	for _, key := range hash.Keys {
+		out.WriteString(indent(depth + 1))
+		out.WriteString(expression(key, depth+1) + ": " + expression(hash.Pairs[key], depth+1))
+		out.WriteString(",\n")
+	}
+	out.WriteString(indent(depth) + "}")
+	return out.String()
+}
+
+// indent returns the indentation for the depth
+func indent(depth int) string {
+	return strings.Repeat("\t", depth)
+}
+
+// isMultiline returns whether the formatted source spans more than one line
+func isMultiline(s string) bool {
+	return strings.Contains(s, "\n")
+}
@@ -0,0 +1,109 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gage-McGuire/kev/lexer"
+	"github.com/Gage-McGuire/kev/parser"
+	"github.com/Gage-McGuire/kev/std"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`var x=1+2*3`, "var x = 1 + 2 * 3;\n"},
+		{`var x = (1 + 2) * 3;`, "var x = (1 + 2) * 3;\n"},
+		{`var x = ((1 * 2)) + 3;`, "var x = 1 * 2 + 3;\n"},
+		{`a - (b - c); (a - b) - c;`, "a - (b - c);\na - b - c;\n"},
+		{`-(a + b); -a[0]; (-a)[0]; !!x; -(-x)`, "-(a + b);\n-a[0];\n(-a)[0];\n!!x;\n--x;\n"},
+		{`(a ?? b) == c; a ?? (b == c)`, "(a ?? b) == c;\na ?? b == c;\n"},
+		{`f(1)(2); (f ?? g)(1); a.b?.c?.[0]?.(1)[1:]`, "f(1)(2);\n(f ?? g)(1);\na.b?.c?.[0]?.(1)[1:];\n"},
+		{`a[:2]; a[1:]; a[:]`, "a[:2];\na[1:];\na[:];\n"},
+		{`[1,2.5,"s",true,null]; {"a":1,2:[]}; {}; []`, "[1, 2.5, \"s\", true, null];\n{\"a\": 1, 2: []};\n{};\n[];\n"},
+		{`import "b.kev" as b; import {x,y as z} from "std/strings"; b.x`, "import \"b.kev\" as b;\nimport { x, y as z } from \"std/strings\";\n\nb.x;\n"},
+		{`var f = func(x) { x * 2 }; var g = 1; f(2); g`, "var f = func(x) { x * 2 };\nvar g = 1;\n\nf(2);\ng;\n"},
+		{`export var f = func(x) { x * 2 }; export var n = 1;`, "export var f = func(x) {\n\tx * 2\n};\n\nexport var n = 1;\n"},
+		{`export var f = func() {}`, "export var f = func() {};\n"},
+		{`var f = func(x) { var y = x; return y; }`, "var f = func(x) {\n\tvar y = x;\n\treturn y;\n};\n"},
+		{`var f = func(x) { if (x) { return 1; } x }`, "var f = func(x) {\n\tif (x) { return 1; }\n\tx\n};\n"},
+		{`if (x > 1) { "big" } else { "small" }`, "if (x > 1) { \"big\" } else { \"small\" }\n"},
+		{`if (x) { a; b } else { c }`, "if (x) {\n\ta;\n\tb\n} else {\n\tc\n}\n"},
+		{`if (x) { 1 }; (-1)`, "if (x) { 1 };\n-1;\n"},
+		{`if (x) { 1 } (-1)`, "if (x) { 1 }(-1);\n"},
+		{`if (x) { 1 }; [1]`, "if (x) { 1 };\n[1];\n"},
+		{`if (x) { 1 } -1`, "if (x) { 1 } - 1;\n"},
+		{`if (x) { 1 } y`, "if (x) { 1 }\ny;\n"},
+		{`map(xs, func(x) { var y = x * 2; y + 1 })`, "map(xs, func(x) {\n\tvar y = x * 2;\n\ty + 1\n});\n"},
+		{`var h = {"f": func(x) { var y = x; y }, "n": 1}`, "var h = {\n\t\"f\": func(x) {\n\t\tvar y = x;\n\t\ty\n\t},\n\t\"n\": 1,\n};\n"},
+		{`var a = [func(x) { var y = x; y }, 1]`, "var a = [\n\tfunc(x) {\n\t\tvar y = x;\n\t\ty\n\t},\n\t1\n];\n"},
+		{"#!/usr/bin/env kev run\r\nprint(1)", "#!/usr/bin/env kev run\nprint(1);\n"},
+		{``, ""},
+	}
+
+	for _, tt := range tests {
+		out, err := Source([]byte(tt.input))
+		if err != nil {
+			t.Errorf("Source(%q) returned error: %s", tt.input, err)
+			continue
+		}
+		if string(out) != tt.expected {
+			t.Errorf("wrong output for %q.\ngot:\n%s\nwant:\n%s", tt.input, out, tt.expected)
+		}
+		assertSameProgram(t, tt.input, string(out))
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	_, err := Source([]byte(`var = 1; var x 2;`))
+	if err == nil {
+		t.Fatalf("Source did not return an error")
+	}
+	if lines := strings.Split(err.Error(), "\n"); len(lines) < 2 {
+		t.Errorf("error did not hold every parsing error. got=%q", err.Error())
+	}
+}
+
+func TestStdIsFormatted(t *testing.T) {
+	for _, name := range std.Names() {
+		for _, read := range []func(string) ([]byte, bool){std.Source, std.Tests} {
+			src, ok := read(name)
+			if !ok {
+				continue
+			}
+			out, err := Source(src)
+			if err != nil {
+				t.Errorf("std module %s did not format: %s", name, err)
+				continue
+			}
+			if string(out) != string(src) {
+				t.Errorf("std module %s is not formatted.\ngot:\n%s", name, out)
+			}
+		}
+	}
+}
+
+// assertSameProgram checks that the formatted source parses to the
+// same program as the input and that formatting it again changes nothing
+func assertSameProgram(t *testing.T, input, formatted string) {
+	t.Helper()
+
+	parse := func(src string) string {
+		p := parser.New(lexer.New(src))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("formatted source %q did not parse: %v", src, p.Errors())
+		}
+		return program.String()
+	}
+	if parse(input) != parse(formatted) {
+		t.Errorf("formatting changed the program.\ninput:\n%s\nformatted:\n%s", input, formatted)
+	}
+
+	again, err := Source([]byte(formatted))
+	if err != nil || string(again) != formatted {
+		t.Errorf("formatting is not stable.\nfirst:\n%s\nsecond:\n%s", formatted, again)
+	}
+}
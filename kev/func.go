@@ -0,0 +1,141 @@
+package kev
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	contextType = reflect.TypeOf((*object.Context)(nil))
+)
+
+// Register binds a builtin function to the given name
+// in the interpreter's global environment. Builtins registered
+// this way only exist in this interpreter
+func (i *Interpreter) Register(name string, fn object.BuiltinFunction) {
+	i.env.Set(name, &object.Builtin{Func: fn})
+}
+
+// RegisterFunc wraps an ordinary Go function as a builtin and binds it
+// to the given name in the interpreter's global environment.
+//
+// When the builtin is called the kev arguments are converted to the
+// function's parameter types with object.ToGo, and the function's result
+// is converted back with object.FromGo. The function may return nothing,
+// a single value, or a value followed by an error. A non-nil error becomes
+// an object.Error, just like a failed conversion does. If the function's
+// first parameter is a *object.Context it is handed the context of the call
+func (i *Interpreter) RegisterFunc(name string, fn interface{}) error {
+	builtin, err := wrapFunc(name, fn)
+	if err != nil {
+		return err
+	}
+	i.env.Set(name, builtin)
+	return nil
+}
+
+// wrapFunc checks the signature of the Go function
+// and returns a builtin that calls it through reflection
+func wrapFunc(name string, fn interface{}) (*object.Builtin, error) {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func {
+		return nil, fmt.Errorf("cannot register %s: got %T, want a function", name, fn)
+	}
+	fnType := fnValue.Type()
+
+	// the function can return nothing, a value,
+	// or a value followed by an error
+	switch fnType.NumOut() {
+	case 0, 1:
+	case 2:
+		if fnType.Out(1) != errorType {
+			return nil, fmt.Errorf("cannot register %s: second result must be error, got %s", name, fnType.Out(1))
+		}
+	default:
+		return nil, fmt.Errorf("cannot register %s: too many results, got=%d", name, fnType.NumOut())
+	}
+
+	// the context isn't one of the arguments
+	// passed to the function from kev
+	wantsContext := fnType.NumIn() > 0 && fnType.In(0) == contextType
+
+	return &object.Builtin{Func: func(ctx *object.Context, args ...object.Object) (obj object.Object) {
+		// a panic in the function becomes an error in kev
+		// instead of taking down the program embedding it
+		defer func() {
+			if r := recover(); r != nil {
+				obj = newError("%s: %v", name, r)
+			}
+		}()
+
+		var in []reflect.Value
+		var err error
+		if wantsContext {
+			in, err = convertArgs(fnType, 1, args)
+			in = append([]reflect.Value{reflect.ValueOf(ctx)}, in...)
+		} else {
+			in, err = convertArgs(fnType, 0, args)
+		}
+		if err != nil {
+			return newError("%s: %s", name, err)
+		}
+
+		out := fnValue.Call(in)
+
+		if fnType.NumOut() == 0 {
+			return object.NULL
+		}
+		if fnType.NumOut() == 2 && !out[1].IsNil() {
+			return newError("%s: %s", name, out[1].Interface().(error))
+		}
+		if fnType.NumOut() == 1 && fnType.Out(0) == errorType {
+			if !out[0].IsNil() {
+				return newError("%s: %s", name, out[0].Interface().(error))
+			}
+			return object.NULL
+		}
+
+		result, err := object.FromGo(out[0].Interface())
+		if err != nil {
+			return newError("%s: %s", name, err)
+		}
+		return result
+	}}, nil
+}
+
+// convertArgs converts the kev arguments into the parameter types
+// of the function being called, skipping the first offset parameters
+func convertArgs(fnType reflect.Type, offset int, args []object.Object) ([]reflect.Value, error) {
+	numIn := fnType.NumIn() - offset
+	if fnType.IsVariadic() {
+		if len(args) < numIn-1 {
+			return nil, fmt.Errorf("wrong number of arguments. got=%d, want at least %d", len(args), numIn-1)
+		}
+	} else if len(args) != numIn {
+		return nil, fmt.Errorf("wrong number of arguments. got=%d, want=%d", len(args), numIn)
+	}
+
+	in := make([]reflect.Value, len(args))
+	for idx, arg := range args {
+		var paramType reflect.Type
+		if fnType.IsVariadic() && idx >= numIn-1 {
+			paramType = fnType.In(offset + numIn - 1).Elem()
+		} else {
+			paramType = fnType.In(offset + idx)
+		}
+		param := reflect.New(paramType)
+		if err := object.ToGo(arg, param.Interface()); err != nil {
+			return nil, fmt.Errorf("argument %d: %s", idx+1, err)
+		}
+		in[idx] = param.Elem()
+	}
+	return in, nil
+}
+
+// creates a new object.Error
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,191 @@
+package kev
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Gage-McGuire/kev/evaluator"
+	"github.com/Gage-McGuire/kev/lexer"
+	"github.com/Gage-McGuire/kev/object"
+	"github.com/Gage-McGuire/kev/parser"
+)
+
+// Interpreter lets Go host programs embed kev.
+// Each interpreter owns its own global environment,
+// so bindings made in one interpreter are never seen by another
+type Interpreter struct {
+	env *object.Environment
+	ctx *object.Context
+}
+
+// ParseError is returned when the source
+// handed to the interpreter could not be parsed.
+// It holds every error message the parser collected
+type ParseError struct {
+	Errors []string
+}
+
+// RuntimeError is returned when evaluating
+// the source ended in an object.Error
+type RuntimeError struct {
+	Message string
+}
+
+// ExitError is returned when the program
+// called exit, and holds the status it exited with
+type ExitError struct {
+	Code int
+}
+
+// Creates a new interpreter with an empty global environment
+// that reads from os.Stdin and writes to os.Stdout and os.Stderr
+func New() *Interpreter {
+	ctx := object.NewContext()
+	env := object.NewEnvironment()
+	env.SetContext(ctx)
+	return &Interpreter{env: env, ctx: ctx}
+}
+
+// Sets the writer the interpreter and its builtins,
+// like print, use for their output
+func (i *Interpreter) SetStdout(w io.Writer) {
+	i.ctx.Stdout = w
+}
+
+// Sets the writer the interpreter and its builtins use for errors
+func (i *Interpreter) SetStderr(w io.Writer) {
+	i.ctx.Stderr = w
+}
+
+// Sets the reader builtins like input read from
+func (i *Interpreter) SetStdin(r io.Reader) {
+	i.ctx.Stdin = r
+}
+
+// Sets the file system builtins like readFile work on and
+// imported modules are read from. Hosts that run untrusted
+// scripts can pass object.ReadOnlyFileSystem, object.RootedFileSystem,
+// object.FromFS or object.DenyFileSystem to limit file access
+func (i *Interpreter) SetFileSystem(fsys object.FileSystem) {
+	i.ctx.FS = fsys
+}
+
+// Sets the clock builtins like time.now read the current time
+// from, so tests can run scripts against a fixed point in time
+func (i *Interpreter) SetClock(clock func() time.Time) {
+	i.ctx.Clock = clock
+}
+
+// Sets the arguments scripts see in args
+func (i *Interpreter) SetArgs(args []string) {
+	i.ctx.Args = args
+}
+
+// Sets the function builtins like env look environment variables up
+// with. It defaults to os.LookupEnv, and nil hides every variable
+func (i *Interpreter) SetLookupEnv(lookup func(name string) (string, bool)) {
+	i.ctx.LookupEnv = lookup
+}
+
+// Returns the writer the interpreter uses for its output
+func (i *Interpreter) Stdout() io.Writer {
+	return i.ctx.Stdout
+}
+
+// Returns the writer the interpreter uses for its errors
+func (i *Interpreter) Stderr() io.Writer {
+	return i.ctx.Stderr
+}
+
+// Binds the object to the given name
+// in the interpreter's global environment
+func (i *Interpreter) SetGlobal(name string, val object.Object) {
+	i.env.Set(name, val)
+}
+
+// Returns the object bound to the given name
+// in the interpreter's global environment
+func (i *Interpreter) GetGlobal(name string) (object.Object, bool) {
+	return i.env.Get(name)
+}
+
+// Eval parses and evaluates the source in the interpreter's
+// global environment, so bindings made by one call are visible
+// to the next. Parsing errors are returned as a *ParseError,
+// a call to exit as an *ExitError and an object.Error produced
+// by the program as a *RuntimeError
+func (i *Interpreter) Eval(src string) (object.Object, error) {
+	return i.eval(src)
+}
+
+// EvalFile reads the file at the path from the interpreter's file
+// system and evaluates it like Eval, resolving the imports in it
+// relative to the file
+func (i *Interpreter) EvalFile(path string) (object.Object, error) {
+	src, err := i.ctx.FileSystem().ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := i.env.File()
+	i.env.SetFile(path)
+	defer i.env.SetFile(previous)
+
+	return i.eval(string(src))
+}
+
+// eval parses and evaluates the source
+// in the interpreter's global environment
+func (i *Interpreter) eval(src string) (object.Object, error) {
+	l := lexer.New(src)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Errors: p.Errors()}
+	}
+
+	evaluated := evaluator.Eval(program, i.env)
+	if errObj, ok := evaluated.(*object.Error); ok {
+		if errObj.Exit {
+			return nil, &ExitError{Code: errObj.Code}
+		}
+		return nil, &RuntimeError{Message: errObj.Message}
+	}
+	return evaluated, nil
+}
+
+// Run evaluates the source like Eval, but writes the result
+// to the interpreter's stdout and any error other than an
+// *ExitError to its stderr
+func (i *Interpreter) Run(src string) error {
+	evaluated, err := i.Eval(src)
+	if err != nil {
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) {
+			io.WriteString(i.ctx.Stderr, err.Error()+"\n")
+		}
+		return err
+	}
+	if evaluated != nil {
+		io.WriteString(i.ctx.Stdout, evaluated.Inspect()+"\n")
+	}
+	return nil
+}
+
+// Returns every parsing error on its own line
+func (pe *ParseError) Error() string {
+	return "parse error: " + strings.Join(pe.Errors, "\n")
+}
+
+// Returns the status the program exited with
+func (ee *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", ee.Code)
+}
+
+// Returns the message of the object.Error
+func (re *RuntimeError) Error() string {
+	return "runtime error: " + re.Message
+}
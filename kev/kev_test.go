@@ -0,0 +1,402 @@
+package kev
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Gage-McGuire/kev/object"
+)
+
+func TestEval(t *testing.T) {
+	interp := New()
+	result, err := interp.Eval("var add = func(x, y) { x + y }; add(2, 3)")
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	integer, ok := result.(*object.Integer)
+	if !ok {
+		t.Fatalf("result is not Integer. got=%T (%+v)", result, result)
+	}
+	if integer.Value != 5 {
+		t.Errorf("result has wrong value. got=%d, want=5", integer.Value)
+	}
+
+	// bindings made by one call are visible to the next
+	result, err = interp.Eval("add(1, 1)")
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result.Inspect() != "2" {
+		t.Errorf("result has wrong value. got=%s, want=2", result.Inspect())
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	interp := New()
+
+	_, err := interp.Eval("var = 5")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("error is not *ParseError. got=%T (%+v)", err, err)
+	}
+	if len(parseErr.Errors) == 0 {
+		t.Errorf("ParseError has no messages")
+	}
+
+	_, err = interp.Eval("5 + true")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("error is not *RuntimeError. got=%T (%+v)", err, err)
+	}
+	if runtimeErr.Message != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong error message. got=%q", runtimeErr.Message)
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	interp := New()
+	interp.SetGlobal("limit", &object.Integer{Value: 10})
+
+	result, err := interp.Eval("var doubled = limit * 2; doubled")
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result.Inspect() != "20" {
+		t.Errorf("result has wrong value. got=%s, want=20", result.Inspect())
+	}
+
+	doubled, ok := interp.GetGlobal("doubled")
+	if !ok {
+		t.Fatalf("global doubled not found")
+	}
+	if doubled.Inspect() != "20" {
+		t.Errorf("doubled has wrong value. got=%s, want=20", doubled.Inspect())
+	}
+
+	if _, ok := New().GetGlobal("doubled"); ok {
+		t.Errorf("global leaked into another interpreter")
+	}
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	interp := New()
+	interp.SetStdout(&stdout)
+	interp.SetStderr(&stderr)
+
+	if err := interp.Run(`"hello" + " " + "world"`); err != nil {
+		t.Fatalf("Run returned error: %s", err)
+	}
+	if stdout.String() != "hello world\n" {
+		t.Errorf("stdout wrong. got=%q", stdout.String())
+	}
+
+	if err := interp.Run("foobar"); err == nil {
+		t.Fatalf("Run did not return an error")
+	}
+	if stderr.String() != "runtime error: identifier not found: foobar\n" {
+		t.Errorf("stderr wrong. got=%q", stderr.String())
+	}
+}
+
+func TestRegister(t *testing.T) {
+	interp := New()
+	interp.Register("answer", func(ctx *object.Context, args ...object.Object) object.Object {
+		return &object.Integer{Value: 42}
+	})
+
+	result, err := interp.Eval("answer()")
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result.Inspect() != "42" {
+		t.Errorf("result has wrong value. got=%s, want=42", result.Inspect())
+	}
+
+	if _, err := New().Eval("answer()"); err == nil {
+		t.Errorf("builtin leaked into another interpreter")
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	interp := New()
+	funcs := map[string]interface{}{
+		"add":   func(a, b int64) int64 { return a + b },
+		"shout": func(s string) string { return s + "!" },
+		"not":   func(b bool) bool { return !b },
+		"sum": func(nums []int) int {
+			total := 0
+			for _, n := range nums {
+				total += n
+			}
+			return total
+		},
+		"join": func(sep string, parts ...string) string {
+			out := ""
+			for i, p := range parts {
+				if i > 0 {
+					out += sep
+				}
+				out += p
+			}
+			return out
+		},
+		"lookup": func(m map[string]int64, key string) int64 { return m[key] },
+		"pair":   func(a, b string) []string { return []string{a, b} },
+		"nums":   func() map[string]int { return map[string]int{"one": 1} },
+		"kind":   func(v interface{}) string { return fmt.Sprintf("%T", v) },
+		"fail":   func() (int64, error) { return 0, errors.New("it failed") },
+		"noop":   func() {},
+	}
+	for name, fn := range funcs {
+		if err := interp.RegisterFunc(name, fn); err != nil {
+			t.Fatalf("RegisterFunc(%q) returned error: %s", name, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`add(2, 3)`, "5"},
+		{`shout("hey")`, "hey!"},
+		{`not(true)`, "false"},
+		{`if (not(false)) { 1 } else { 2 }`, "1"},
+		{`sum([1, 2, 3])`, "6"},
+		{`join(", ", "a", "b", "c")`, "a, b, c"},
+		{`lookup({"a": 1, "b": 2}, "b")`, "2"},
+		{`pair("x", "y")`, "[x, y]"},
+		{`nums()["one"]`, "1"},
+		{`kind([1, "two"])`, "[]interface {}"},
+		{`kind({"a": 1})`, "map[string]interface {}"},
+		{`noop()`, "null"},
+	}
+	for _, tt := range tests {
+		result, err := interp.Eval(tt.input)
+		if err != nil {
+			t.Errorf("Eval(%q) returned error: %s", tt.input, err)
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("Eval(%q) wrong. got=%s, want=%s", tt.input, result.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestRegisterFuncErrors(t *testing.T) {
+	interp := New()
+	if err := interp.RegisterFunc("five", 5); err == nil {
+		t.Errorf("registering a non-function did not return an error")
+	}
+	if err := interp.RegisterFunc("bad", func() (int, int) { return 0, 0 }); err == nil {
+		t.Errorf("registering a function with a non-error second result did not return an error")
+	}
+
+	interp.RegisterFunc("add", func(a, b int64) int64 { return a + b })
+	interp.RegisterFunc("small", func(n int8) int8 { return n })
+	interp.RegisterFunc("fail", func() (int64, error) { return 0, errors.New("it failed") })
+	interp.RegisterFunc("crash", func(idx int) int { return []int{}[idx] })
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`add(1)`, "add: wrong number of arguments. got=1, want=2"},
+		{`add(1, "2")`, "add: argument 2: cannot convert STRING to int64"},
+		{`small(300)`, "small: argument 1: 300 overflows int8"},
+		{`fail()`, "fail: it failed"},
+		{`crash(1)`, "crash: runtime error: index out of range [1] with length 0"},
+	}
+	for _, tt := range tests {
+		_, err := interp.Eval(tt.input)
+		var runtimeErr *RuntimeError
+		if !errors.As(err, &runtimeErr) {
+			t.Errorf("Eval(%q) did not return a *RuntimeError. got=%T (%+v)", tt.input, err, err)
+			continue
+		}
+		if runtimeErr.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, runtimeErr.Message)
+		}
+	}
+}
+
+func TestRedirectedIO(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	interp := New()
+	interp.SetStdout(&stdout)
+	interp.SetStderr(&stderr)
+	interp.SetStdin(strings.NewReader("world\n"))
+
+	if _, err := interp.Eval(`print("hello " + input()); eprint("done")`); err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if stdout.String() != "hello world\n" {
+		t.Errorf("stdout wrong. got=%q", stdout.String())
+	}
+	if stderr.String() != "done\n" {
+		t.Errorf("stderr wrong. got=%q", stderr.String())
+	}
+}
+
+func TestEvalFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.kev":      `import "lib/greet.kev" as greet; greet.hello("kev")`,
+		"lib/greet.kev": `export var hello = func(name) { "hello " + name };`,
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	interp := New()
+	result, err := interp.EvalFile(filepath.Join(dir, "main.kev"))
+	if err != nil {
+		t.Fatalf("EvalFile returned error: %s", err)
+	}
+	if result.Inspect() != "hello kev" {
+		t.Errorf("wrong result. got=%s", result.Inspect())
+	}
+
+	// the module binding stays in the global environment
+	result, err = interp.Eval(`greet.hello("again")`)
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result.Inspect() != "hello again" {
+		t.Errorf("wrong result. got=%s", result.Inspect())
+	}
+
+	if _, err := interp.EvalFile(filepath.Join(dir, "missing.kev")); err == nil {
+		t.Errorf("EvalFile did not return an error for a missing file")
+	}
+}
+
+func TestShebang(t *testing.T) {
+	interp := New()
+	interp.SetFileSystem(object.FromFS(fstest.MapFS{
+		"script.kev": {Data: []byte("#!/usr/bin/env -S kev run\nvar x = 2; x * 3")},
+		"crlf.kev":   {Data: []byte("#!/usr/bin/env kev run\r\n\r\nx + 1")},
+	}))
+
+	tests := []struct {
+		eval     func() (object.Object, error)
+		expected string
+	}{
+		{func() (object.Object, error) { return interp.Eval("#!/bin/kev\n1 + 1") }, "2"},
+		{func() (object.Object, error) { return interp.Eval("#!") }, "<nil>"},
+		{func() (object.Object, error) { return interp.EvalFile("script.kev") }, "6"},
+		{func() (object.Object, error) { return interp.EvalFile("crlf.kev") }, "3"},
+	}
+	for i, tt := range tests {
+		result, err := tt.eval()
+		if err != nil {
+			t.Errorf("tests[%d] returned error: %s", i, err)
+			continue
+		}
+		got := "<nil>"
+		if result != nil {
+			got = result.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("tests[%d] wrong result. got=%s, want=%s", i, got, tt.expected)
+		}
+	}
+
+	// only the very first line can be a shebang
+	if _, err := interp.Eval("1;\n#!/bin/kev"); err == nil {
+		t.Errorf("Eval did not return an error for a shebang after the first line")
+	}
+	if _, err := interp.Eval(" #!/bin/kev\n1"); err == nil {
+		t.Errorf("Eval did not return an error for a shebang after a space")
+	}
+}
+
+func TestSetFileSystem(t *testing.T) {
+	interp := New()
+	interp.SetFileSystem(object.FromFS(fstest.MapFS{
+		"main.kev":      {Data: []byte(`import "lib/greet.kev" as greet; greet.hello(readFile("name.txt"))`)},
+		"lib/greet.kev": {Data: []byte(`export var hello = func(name) { "hello " + name };`)},
+		"name.txt":      {Data: []byte("kev")},
+	}))
+
+	result, err := interp.EvalFile("main.kev")
+	if err != nil {
+		t.Fatalf("EvalFile returned error: %s", err)
+	}
+	if result.Inspect() != "hello kev" {
+		t.Errorf("wrong result. got=%s", result.Inspect())
+	}
+
+	if _, err := interp.Eval(`writeFile("name.txt", "x")`); err == nil {
+		t.Errorf("writeFile did not return an error on a read-only file system")
+	}
+
+	interp.SetFileSystem(object.DenyFileSystem())
+	_, err = interp.EvalFile("main.kev")
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("EvalFile did not return a permission error. got=%v", err)
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	interp := New()
+	interp.SetClock(func() time.Time {
+		return time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	})
+
+	result, err := interp.Eval(`time.format(time.now() + time.HOUR, time.KITCHEN)`)
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result.Inspect() != "1:30PM" {
+		t.Errorf("wrong result. got=%s", result.Inspect())
+	}
+}
+
+func TestArgsEnvAndExit(t *testing.T) {
+	var stderr bytes.Buffer
+	interp := New()
+	interp.SetStderr(&stderr)
+	interp.SetArgs([]string{"one", "two"})
+	interp.SetLookupEnv(func(name string) (string, bool) {
+		return "value of " + name, name == "KEV_TEST"
+	})
+
+	result, err := interp.Eval(`args[1] + " " + env("KEV_TEST") + " " + env("OTHER", "default")`)
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result.Inspect() != "two value of KEV_TEST default" {
+		t.Errorf("wrong result. got=%s", result.Inspect())
+	}
+
+	err = interp.Run(`exit(7); 1`)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 7 {
+		t.Fatalf("Run did not return an *ExitError with code 7. got=%v", err)
+	}
+	if err.Error() != "exit status 7" {
+		t.Errorf("wrong error message. got=%q", err.Error())
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Run wrote the exit to stderr. got=%q", stderr.String())
+	}
+
+	_, err = interp.Eval(`missing`)
+	if errors.As(err, &exitErr) {
+		t.Errorf("a runtime error was returned as an *ExitError")
+	}
+}
@@ -12,9 +12,22 @@ type Lexer struct {
 func New(input string) *Lexer {
 	l := &Lexer{input: input}
 	l.readChar()
+	l.skipShebang()
 	return l
 }
 
+// helper function to skip the shebang line,
+// e.g. #!/usr/bin/env kev run, which makes a file
+// executable but is only allowed at the very start of it
+func (l *Lexer) skipShebang() {
+	if l.ch != '#' || l.peekChar() != '!' {
+		return
+	}
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+}
+
 // gives us the next character and
 // advances our position in the input string
 func (l *Lexer) readChar() {
@@ -55,10 +68,25 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			next_token = newToken(token.BANG, l.ch)
 		}
+	case '?':
+		switch l.peekChar() {
+		case '?':
+			ch := l.ch
+			l.readChar()
+			next_token = token.Token{Type: token.NULLISH, Literal: string(ch) + string(l.ch)}
+		case '.':
+			ch := l.ch
+			l.readChar()
+			next_token = token.Token{Type: token.QUESTION_DOT, Literal: string(ch) + string(l.ch)}
+		default:
+			next_token = newToken(token.ILLEGAL, l.ch)
+		}
 	case ';':
 		next_token = newToken(token.SEMICOLON, l.ch)
 	case ':':
 		next_token = newToken(token.COLON, l.ch)
+	case '.':
+		next_token = newToken(token.DOT, l.ch)
 	case '(':
 		next_token = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -97,8 +125,7 @@ func (l *Lexer) NextToken() token.Token {
 			next_token.Type = token.LookupIdent(next_token.Literal)
 			return next_token
 		} else if isDigit(l.ch) {
-			next_token.Type = token.INT
-			next_token.Literal = l.readNumber()
+			next_token.Literal, next_token.Type = l.readNumber()
 			return next_token
 		} else {
 			next_token = newToken(token.ILLEGAL, l.ch)
@@ -115,10 +142,12 @@ func newToken(tokenType token.TokenType, ch byte) token.Token {
 
 // helper function to read an identifier
 // and advance the lexer's position in the input string
-// until it encounters a non-letter character
+// until it encounters a character that is not a letter or digit.
+// Identifiers always start with a letter, so digits are
+// only allowed after the first character, e.g. log2
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
@@ -126,13 +155,23 @@ func (l *Lexer) readIdentifier() string {
 
 // helper function to read a number
 // and advance the lexer's position in the input string
-// until it encounters a non-digit character
-func (l *Lexer) readNumber() string {
+// until it encounters a non-digit character.
+// Returns the number along with its token type,
+// which is token.FLOAT when it has a fraction
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	position := l.position
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	// a dot followed by a digit makes the number a float
+	if l.ch != '.' || !isDigit(l.peekChar()) {
+		return l.input[position:l.position], token.INT
+	}
+	l.readChar()
+	for isDigit(l.ch) {
+		l.readChar()
+	}
+	return l.input[position:l.position], token.FLOAT
 }
 
 // helper function to read a string
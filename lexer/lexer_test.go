@@ -33,6 +33,9 @@ func TestNextToken(t *testing.T) {
 	"foo bar"
 	[1,2];
 	{"foo": "bar"}
+	3.14;
+	null ?? a?.[0];
+	log2 x1y;
 	`
 	tests := []struct {
 		expectedType    token.TokenType
@@ -146,6 +149,19 @@ func TestNextToken(t *testing.T) {
 		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
+		{token.FLOAT, "3.14"},
+		{token.SEMICOLON, ";"},
+		{token.NULL, "null"},
+		{token.NULLISH, "??"},
+		{token.IDENT, "a"},
+		{token.QUESTION_DOT, "?."},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "log2"},
+		{token.IDENT, "x1y"},
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
 	}
 
@@ -161,3 +177,79 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestShebang(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Token
+	}{
+		{"#!/usr/bin/env kev run\nvar x = 1;", []token.Token{
+			{Type: token.VAR, Literal: "var"},
+			{Type: token.IDENT, Literal: "x"},
+			{Type: token.ASSIGN, Literal: "="},
+			{Type: token.INT, Literal: "1"},
+			{Type: token.SEMICOLON, Literal: ";"},
+			{Type: token.EOF, Literal: ""},
+		}},
+		{"#!/usr/bin/env kev run", []token.Token{
+			{Type: token.EOF, Literal: ""},
+		}},
+		// only a shebang at the very start of the input is skipped
+		{" #!x", []token.Token{
+			{Type: token.ILLEGAL, Literal: "#"},
+			{Type: token.BANG, Literal: "!"},
+			{Type: token.IDENT, Literal: "x"},
+			{Type: token.EOF, Literal: ""},
+		}},
+		{"x\n#!y", []token.Token{
+			{Type: token.IDENT, Literal: "x"},
+			{Type: token.ILLEGAL, Literal: "#"},
+			{Type: token.BANG, Literal: "!"},
+			{Type: token.IDENT, Literal: "y"},
+			{Type: token.EOF, Literal: ""},
+		}},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for i, expected := range tt.expected {
+			tok := l.NextToken()
+			if tok != expected {
+				t.Fatalf("input %q tokens[%d] wrong. expected=%+v, got=%+v", tt.input, i, expected, tok)
+			}
+		}
+	}
+}
+
+func TestIdentifiersWithDigits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Token
+	}{
+		{"log2", []token.Token{{Type: token.IDENT, Literal: "log2"}}},
+		{"a1b2c3", []token.Token{{Type: token.IDENT, Literal: "a1b2c3"}}},
+		{"_9", []token.Token{{Type: token.IDENT, Literal: "_9"}}},
+		{"2x", []token.Token{{Type: token.INT, Literal: "2"}, {Type: token.IDENT, Literal: "x"}}},
+		{"x2.5", []token.Token{{Type: token.IDENT, Literal: "x2"}, {Type: token.DOT, Literal: "."}, {Type: token.INT, Literal: "5"}}},
+		{"var v1 = f2(x3)", []token.Token{
+			{Type: token.VAR, Literal: "var"},
+			{Type: token.IDENT, Literal: "v1"},
+			{Type: token.ASSIGN, Literal: "="},
+			{Type: token.IDENT, Literal: "f2"},
+			{Type: token.LPAREN, Literal: "("},
+			{Type: token.IDENT, Literal: "x3"},
+			{Type: token.RPAREN, Literal: ")"},
+		}},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for i, expected := range append(tt.expected, token.Token{Type: token.EOF}) {
+			tok := l.NextToken()
+			if tok.Type != expected.Type || tok.Literal != expected.Literal {
+				t.Errorf("%q: token %d wrong. expected=%s %q, got=%s %q", tt.input, i, expected.Type, expected.Literal, tok.Type, tok.Literal)
+				break
+			}
+		}
+	}
+}
@@ -1,37 +1,37 @@
 package main
 
 import (
-	"fmt"
+	_ "embed"
 	"os"
+	"runtime/debug"
 
-	repl "github.com/Gage-McGuire/kev/repl"
+	// time zones are embedded so the time namespace can
+	// load them on systems without a zoneinfo database.
+	// It's done here rather than in the evaluator so
+	// programs embedding kev don't have to carry them
+	_ "time/tzdata"
 )
 
-func main() {
-	if len(os.Args) > 1 {
-		cmd := os.Args[1]
-		switch cmd {
-		case "run":
-			if len(os.Args) < 3 {
-				fmt.Println("Usage: kev run <file>")
-				os.Exit(1)
-			}
-			fileName := os.Args[2]
-			repl.RunFile(fileName)
-		default:
-			fmt.Println("Usage: kev run <file>")
-			os.Exit(1)
-		}
-	} else {
-		kevBanner()
-		repl.RunPrompt(os.Stdin, os.Stdout)
+// banner is shown when the repl starts. It's embedded
+// so kev works from any working directory
+//
+//go:embed kev-banner.txt
+var banner string
+
+// version is the version kev reports. Release builds set it with
+// go build -ldflags "-X main.version=v1.2.3", and builds installed
+// with go install fall back to the version of the module
+var version = "dev"
+
+func init() {
+	if version != "dev" {
+		return
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
 	}
 }
 
-func kevBanner() {
-	banner, err := os.ReadFile("kev-banner.txt")
-	if err != nil {
-		panic(err)
-	}
-	fmt.Println(string(banner))
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runKev runs kev with the arguments and stdin
+// and returns its output and exit status
+func runKev(stdin string, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+// writeFiles writes the files to a temporary
+// directory and returns the directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCommands(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.kev":                 "#!/usr/bin/env kev run\nimport \"lib.kev\" as lib; lib.greet(args[0])",
+		"lib.kev":                  `export var greet = func(name) { "hello " + name };`,
+		"exit.kev":                 `exit(len(args))`,
+		"broken.kev":               `var = 1;`,
+		"failing.kev":              `missing`,
+		"messy.kev":                `var x=1`,
+		"tests/a_test.kev":         `assertEqual(1 + 1, 2); print("from a");`,
+		"tests/b_test.kev":         `print("from b"); assertEqual(1, 2);`,
+		"tests/.hidden/c_test.kev": `assertEqual(1, 2);`,
+		"tests/helper.kev":         `assertEqual(1, 2);`,
+		"passing/x_test.kev":       `assert(true); exit(0); assert(false);`,
+		"empty/x.kev":              `1`,
+	})
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	tests := []struct {
+		name   string
+		stdin  string
+		args   []string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{"run", "", []string{"run", path("main.kev"), "kev"}, "hello kev\n", "", 0},
+		{"run exit status", "", []string{"run", path("exit.kev"), "a", "-b", "c"}, "", "", 3},
+		{"run parse error", "", []string{"run", path("broken.kev")}, "PARSING ERROR", "", 1},
+		{"run runtime error", "", []string{"run", path("failing.kev")}, "ERROR: identifier not found: missing\n", "", 1},
+		{"run missing file", "", []string{"run", path("missing.kev")}, "", "no such file or directory", 1},
+		{"run stdin", `args[0] + "!"`, []string{"run", "-", "hi"}, "hi!\n", "", 0},
+		{"run without file", "", []string{"run"}, "", "kev run: no file given\nusage: kev run <file|-> [args...]", 2},
+		{"eval", "", []string{"-e", `args[1] + " " + str(math.sqrt(16))`, "a", "b"}, "b 4.0\n", "", 0},
+		{"eval exit status", "", []string{"-e", `exit(4)`}, "", "", 4},
+		{"version", "", []string{"version"}, "kev " + version + "\n", "", 0},
+		{"help", "", []string{"help"}, "commands:\n\trun      run a kev script\n", "", 0},
+		{"help command", "", []string{"help", "test"}, "usage: kev test [flags] [paths...]", "", 0},
+		{"help flags", "", []string{"help", "fmt"}, "  -w\twrite the formatted source back", "", 0},
+		{"help unknown command", "", []string{"help", "nope"}, "", `kev help: unknown command "nope"`, 2},
+		{"command -h", "", []string{"check", "-h"}, "", "usage: kev check [paths...]", 0},
+		{"unknown flag", "", []string{"test", "-nope"}, "", "flag provided but not defined: -nope", 2},
+		{"unknown command", "", []string{"nope"}, "", `kev: unknown command "nope"`, 2},
+		{"kev -h", "", []string{"-h"}, "", "kev -e <source> [args...]", 0},
+		{"repl", "1 + 2\nexit()\n3\n", []string{"repl", "-quiet"}, ">> 3\n>> ", "", 0},
+		{"repl input", "var name = input()\nkev\nname + \"!\"\n", []string{"repl", "-quiet"}, ">> >> kev!\n>> ", "", 0},
+		{"repl banner", "", []string{"repl"}, strings.TrimSpace(banner), "", 0},
+		{"repl without command", "1\n", nil, ">> 1\n", "", 0},
+		{"fmt stdin", "var x=1+2", []string{"fmt"}, "var x = 1 + 2;\n", "", 0},
+		{"fmt file", "", []string{"fmt", path("messy.kev")}, "var x = 1;\n", "", 0},
+		{"fmt list", "", []string{"fmt", "-l", path("messy.kev"), path("lib.kev")}, path("messy.kev") + "\n", "", 0},
+		{"fmt parse error", "", []string{"fmt", path("broken.kev")}, "", path("broken.kev") + ": expected next token to be IDENT, got =", 1},
+		{"check", "", []string{"check", path("main.kev"), path("lib.kev")}, "", "", 0},
+		{"check errors", "", []string{"check", path("broken.kev")}, "", path("broken.kev") + ": no prefix parse function for = found\n", 1},
+		{"check stdin", "var x 1", []string{"check", "-"}, "", "<stdin>: expected next token to be =, got INT\n", 1},
+		{"test", "", []string{"test", path("tests")}, "ok   " + path("tests/a_test.kev") + "\nFAIL " + path("tests/b_test.kev") + "\n     from b\n     assertion failed: expected 2, got 1\n1 passed, 1 failed\n", "", 1},
+		{"test verbose", "", []string{"test", "-v", path("tests/a_test.kev")}, "from a\nok   " + path("tests/a_test.kev") + "\n1 passed, 0 failed\n", "", 0},
+		{"test exit", "", []string{"test", path("passing")}, "ok   " + path("passing/x_test.kev") + "\n", "", 0},
+		{"test std", "", []string{"test", "-std", path("passing")}, "ok   std/strings\n", "", 0},
+		{"test no files", "", []string{"test", path("empty")}, "no test files\n", "", 0},
+	}
+
+	for _, tt := range tests {
+		stdout, stderr, code := runKev(tt.stdin, tt.args...)
+		if code != tt.code {
+			t.Errorf("%s: wrong exit status. got=%d, want=%d\nstdout: %s\nstderr: %s", tt.name, code, tt.code, stdout, stderr)
+		}
+		if !strings.Contains(stdout, tt.stdout) {
+			t.Errorf("%s: stdout does not contain %q. got=%q", tt.name, tt.stdout, stdout)
+		}
+		if !strings.Contains(stderr, tt.stderr) {
+			t.Errorf("%s: stderr does not contain %q. got=%q", tt.name, tt.stderr, stderr)
+		}
+	}
+}
+
+func TestStdinAndEval(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdin  string
+		args   []string
+		stdout string
+		code   int
+	}{
+		{"stdin", "1 + 2", []string{"run", "-"}, "3\n", 0},
+		{"stdin shebang", "#!/usr/bin/env -S kev run\nlen(args)", []string{"run", "-", "a", "b"}, "2\n", 0},
+		{"stdin args", "args", []string{"run", "-", "-x", "--", "y"}, "[-x, --, y]\n", 0},
+		{"stdin parse error", "var = 1", []string{"run", "-"}, "PARSING ERROR", 1},
+		{"stdin runtime error", "missing", []string{"run", "-"}, "ERROR: identifier not found: missing\n", 1},
+		{"stdin exit", "exit(9); 1", []string{"run", "-"}, "", 9},
+		{"empty stdin", "", []string{"run", "-"}, "", 0},
+		{"eval", "", []string{"-e", "1 + 2"}, "3\n", 0},
+		{"eval without args", "", []string{"-e", "args"}, "[]\n", 0},
+		{"eval args", "", []string{"-e", "args", "--", "-x", "y"}, "[-x, y]\n", 0},
+		{"eval reads stdin", "kev\n", []string{"-e", `"hi " + input()`}, "hi kev\n", 0},
+		{"eval shebang", "", []string{"-e", "#!kev\n5"}, "5\n", 0},
+		{"eval parse error", "", []string{"-e", "var = 1"}, "PARSING ERROR", 1},
+		{"eval runtime error", "", []string{"-e", `1 + "a"`}, "ERROR: type mismatch: INTEGER + STRING\n", 1},
+	}
+
+	for _, tt := range tests {
+		stdout, stderr, code := runKev(tt.stdin, tt.args...)
+		if code != tt.code {
+			t.Errorf("%s: wrong exit status. got=%d, want=%d\nstdout: %s\nstderr: %s", tt.name, code, tt.code, stdout, stderr)
+		}
+		if tt.stdout == "" && stdout != "" || !strings.Contains(stdout, tt.stdout) {
+			t.Errorf("%s: wrong stdout. got=%q, want=%q", tt.name, stdout, tt.stdout)
+		}
+	}
+}
+
+func TestFmtWrite(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.kev":     `var x=1`,
+		"sub/b.kev": "var y = 2;\n",
+		"c.txt":     `var z=3`,
+	})
+
+	if _, stderr, code := runKev("", "fmt", "-w", dir); code != 0 {
+		t.Fatalf("kev fmt -w failed with status %d: %s", code, stderr)
+	}
+	for name, expected := range map[string]string{"a.kev": "var x = 1;\n", "sub/b.kev": "var y = 2;\n", "c.txt": `var z=3`} {
+		src, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(src) != expected {
+			t.Errorf("wrong contents of %s. got=%q, want=%q", name, src, expected)
+		}
+	}
+
+	if stdout, _, _ := runKev("", "fmt", "-l", dir); stdout != "" {
+		t.Errorf("kev fmt -l listed formatted files: %q", stdout)
+	}
+}
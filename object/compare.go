@@ -0,0 +1,199 @@
+package object
+
+import (
+	"math"
+	"strings"
+)
+
+// Equal checks if two objects hold the same value.
+// Numbers, strings and booleans are compared by value,
+// where an integer and a float are equal when they hold
+// the same number,
+// arrays and hashes are compared element by element, so
+// nested arrays and hashes are compared all the way down.
+// Two hashes are equal when they hold the same pairs, no
+// matter the order the pairs were set in. Objects that
+// implement Comparable are compared with Compare, and
+// every other object is only equal to itself
+func Equal(a, b Object) bool {
+	switch a := a.(type) {
+	case *Integer, *Float:
+		cmp, ok := compareNumbers(a, b)
+		return ok && cmp == 0
+	case *String:
+		if b, ok := b.(*String); ok {
+			return a.Value == b.Value
+		}
+		return false
+	case *Boolean:
+		if b, ok := b.(*Boolean); ok {
+			return a.Value == b.Value
+		}
+		return false
+	case *Array:
+		b, ok := b.(*Array)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		for idx, el := range a.Elements {
+			if !Equal(el, b.Elements[idx]) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		b, ok := b.(*Hash)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for _, pair := range a.Pairs() {
+			value, ok := b.Get(pair.Key)
+			if !ok || !Equal(pair.Value, value) {
+				return false
+			}
+		}
+		return true
+	case Comparable:
+		cmp, ok := a.Compare(b)
+		return ok && cmp == 0
+	}
+	return a == b
+}
+
+// Compare orders two objects of the same type. It returns a negative
+// number when a comes before b, zero when they are equal and a positive
+// number when a comes after b. Numbers are ordered by value, so
+// integers and floats can be ordered against each other, strings
+// byte by byte, false comes before true, and arrays are ordered
+// lexicographically by their elements, with a shorter array coming
+// first when it's the start of the longer one. Objects that implement
+// Comparable are ordered with Compare. It returns false when the
+// objects can't be ordered, like when their types are different
+func Compare(a, b Object) (int, bool) {
+	switch a := a.(type) {
+	case *Integer, *Float:
+		return compareNumbers(a, b)
+	case *String:
+		b, ok := b.(*String)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a.Value, b.Value), true
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a.Value == b.Value:
+			return 0, true
+		case b.Value:
+			return -1, true
+		default:
+			return 1, true
+		}
+	case *Array:
+		b, ok := b.(*Array)
+		if !ok {
+			return 0, false
+		}
+		for idx := 0; idx < len(a.Elements) && idx < len(b.Elements); idx++ {
+			cmp, ok := Compare(a.Elements[idx], b.Elements[idx])
+			if !ok {
+				return 0, false
+			}
+			if cmp != 0 {
+				return cmp, true
+			}
+		}
+		return len(a.Elements) - len(b.Elements), true
+	case Comparable:
+		return a.Compare(b)
+	}
+	return 0, false
+}
+
+// compareNumbers orders two numbers. Two integers or two floats
+// are compared as they are, and an integer is compared with a
+// float exactly, without rounding the integer to a float first.
+// It returns false if either isn't a number or either is NaN
+func compareNumbers(a, b Object) (int, bool) {
+	switch a := a.(type) {
+	case *Integer:
+		switch b := b.(type) {
+		case *Integer:
+			return compareInts(a.Value, b.Value), true
+		case *Float:
+			return compareIntFloat(a.Value, b.Value)
+		}
+	case *Float:
+		switch b := b.(type) {
+		case *Integer:
+			cmp, ok := compareIntFloat(b.Value, a.Value)
+			return -cmp, ok
+		case *Float:
+			switch {
+			case a.Value < b.Value:
+				return -1, true
+			case a.Value > b.Value:
+				return 1, true
+			case a.Value == b.Value:
+				return 0, true
+			}
+			// NaN can't be ordered
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// compareInts orders two integers
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareIntFloat orders an integer and a float exactly. Floats
+// outside of the integers are larger or smaller than all of them,
+// and the rest are compared by their whole part, then by whatever
+// is left after the point
+func compareIntFloat(i int64, f float64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case f >= 1<<63:
+		return -1, true
+	case f < -(1 << 63):
+		return 1, true
+	}
+	whole := math.Trunc(f)
+	if cmp := compareInts(i, int64(whole)); cmp != 0 {
+		return cmp, true
+	}
+	switch {
+	case f > whole:
+		return -1, true
+	case f < whole:
+		return 1, true
+	}
+	return 0, true
+}
+
+// ToFloat returns the value of an integer or float
+// object as a float64. It returns false for any other object
+func ToFloat(obj Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value), true
+	case *Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,140 @@
+package object
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Context holds everything a builtin function needs from
+// the program that is running kev, like where to write its
+// output and where to read its input from
+type Context struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	Stdin  io.Reader
+
+	// FS is the file system builtins like readFile
+	// work on and imported modules are read from
+	FS FileSystem
+
+	// Clock returns the current time for builtins like
+	// time.now. Tests can replace it with a fixed clock
+	Clock func() time.Time
+
+	// Args are the arguments passed to the script,
+	// e.g. [a, b] for kev run script.kev a b
+	Args []string
+
+	// LookupEnv returns the value of the environment variable
+	// and whether it is set, for builtins like env
+	LookupEnv func(name string) (string, bool)
+
+	// stdin wrapped in a reader that can read line by line.
+	// It's kept around so buffered input isn't lost between reads
+	stdinReader *bufio.Reader
+	stdinSource io.Reader
+
+	// modules imported so far, keyed by their path,
+	// so every module is only evaluated once
+	modules map[string]*Module
+
+	// paths of the modules being imported right now,
+	// in the order they were imported, used to detect cycles
+	importing []string
+}
+
+// Creates a new context that reads from os.Stdin, writes to
+// os.Stdout and os.Stderr, works on the operating system's files
+// and reads the process's environment variables
+func NewContext() *Context {
+	return &Context{
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+		Stdin:     os.Stdin,
+		FS:        OSFileSystem(),
+		Clock:     time.Now,
+		LookupEnv: os.LookupEnv,
+	}
+}
+
+// Returns the value of the environment variable and whether it
+// is set. A context without LookupEnv has no environment variables
+func (c *Context) Getenv(name string) (string, bool) {
+	if c.LookupEnv == nil {
+		return "", false
+	}
+	return c.LookupEnv(name)
+}
+
+// Returns the current time from the context's clock,
+// or from the system clock when it has none
+func (c *Context) Now() time.Time {
+	if c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock()
+}
+
+// Returns the context's file system. A context
+// without one can't touch any files
+func (c *Context) FileSystem() FileSystem {
+	if c.FS == nil {
+		return DenyFileSystem()
+	}
+	return c.FS
+}
+
+// Reads the next line from the context's stdin
+// without the trailing line break. It returns io.EOF
+// once there is nothing left to read
+func (c *Context) ReadLine() (string, error) {
+	if c.stdinReader == nil || c.stdinSource != c.Stdin {
+		c.stdinReader = bufio.NewReader(c.Stdin)
+		c.stdinSource = c.Stdin
+	}
+
+	line, err := c.stdinReader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, err
+}
+
+// Returns the module that was already imported from the path
+func (c *Context) Module(path string) (*Module, bool) {
+	module, ok := c.modules[path]
+	return module, ok
+}
+
+// Stores the module imported from the path, so importing
+// the path again returns it instead of evaluating it again
+func (c *Context) SetModule(path string, module *Module) {
+	if c.modules == nil {
+		c.modules = make(map[string]*Module)
+	}
+	c.modules[path] = module
+}
+
+// Marks the module at the path as being imported. When the path
+// is already being imported the imports form a cycle, so it returns
+// false along with the chain of paths that leads back to the path
+func (c *Context) StartImport(path string) ([]string, bool) {
+	for idx, importing := range c.importing {
+		if importing == path {
+			cycle := append([]string{}, c.importing[idx:]...)
+			return append(cycle, path), false
+		}
+	}
+	c.importing = append(c.importing, path)
+	return nil, true
+}
+
+// Marks the module imported last as done being imported
+func (c *Context) FinishImport() {
+	c.importing = c.importing[:len(c.importing)-1]
+}
@@ -0,0 +1,414 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var objectType = reflect.TypeOf((*Object)(nil)).Elem()
+
+// FromGo converts a Go value into a kev object.
+//
+// Integers become INTEGER, floats become FLOAT, strings become
+// STRING, bools become BOOLEAN, slices and arrays become ARRAY
+// and maps become HASH.
+// Structs also become HASH, keyed by the name in the field's
+// `kev:"name"` tag or by the field name when it has no tag. Fields
+// tagged `kev:"-"` and unexported fields are skipped. Pointers and
+// interfaces are followed, and nil becomes NULL. Values that are
+// already objects are returned untouched. A value that refers back
+// to itself through a pointer or a map can't be converted and
+// returns an error
+func FromGo(value interface{}) (Object, error) {
+	return fromGoValue(reflect.ValueOf(value), map[uintptr]bool{})
+}
+
+// ToGo converts a kev object into the Go value target points to.
+// It follows the same rules as FromGo in reverse. A HASH converted
+// into a struct sets each field from the key with the field's name,
+// and fields without a key are left untouched. Converting into an
+// empty interface gives int64, float64, string, bool, []interface{} or
+// map[string]interface{} depending on the object. A HASH with any key
+// that isn't a STRING gives map[interface{}]interface{} instead.
+// A nil object is converted like NULL
+func ToGo(obj Object, target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("cannot convert into %T, want a non-nil pointer", target)
+	}
+	return toGoValue(obj, ptr.Elem())
+}
+
+// fromGoValue converts the reflected Go value into a kev object.
+// seen holds the pointers and maps being converted right now,
+// so a value that refers back to itself is caught
+func fromGoValue(value reflect.Value, seen map[uintptr]bool) (Object, error) {
+	if !value.IsValid() {
+		return NULL, nil
+	}
+	if value.Type().Implements(objectType) {
+		if isNilValue(value) {
+			return NULL, nil
+		}
+		return value.Interface().(Object), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Integer{Value: value.Int()}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > 1<<63-1 {
+			return nil, fmt.Errorf("%d overflows %s", value.Uint(), INTEGER_OBJ)
+		}
+		return &Integer{Value: int64(value.Uint())}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return &Float{Value: value.Float()}, nil
+
+	case reflect.String:
+		return &String{Value: value.String()}, nil
+
+	case reflect.Bool:
+		return NativeBool(value.Bool()), nil
+
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return NULL, nil
+		}
+		elements := make([]Object, value.Len())
+		for idx := range elements {
+			el, err := fromGoValue(value.Index(idx), seen)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %s", idx, err)
+			}
+			elements[idx] = el
+		}
+		return &Array{Elements: elements}, nil
+
+	case reflect.Map:
+		if value.IsNil() {
+			return NULL, nil
+		}
+		if seen[value.Pointer()] {
+			return nil, cycleError(value)
+		}
+		seen[value.Pointer()] = true
+		defer delete(seen, value.Pointer())
+
+		// Go maps have no order, so the keys are sorted
+		// to give the hash the same order every time
+		keys := value.MapKeys()
+		sort.Slice(keys, func(a, b int) bool {
+			return lessMapKey(keys[a], keys[b])
+		})
+		hash := NewHash()
+		for _, mapKey := range keys {
+			key, err := fromGoValue(mapKey, seen)
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %s", mapKey, err)
+			}
+			val, err := fromGoValue(value.MapIndex(mapKey), seen)
+			if err != nil {
+				return nil, fmt.Errorf("value of key %v: %s", mapKey, err)
+			}
+			if !hash.Set(key, val) {
+				return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
+			}
+		}
+		return hash, nil
+
+	case reflect.Struct:
+		hash := NewHash()
+		for _, field := range structFields(value.Type()) {
+			val, err := fromGoValue(value.Field(field.index), seen)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %s", field.name, err)
+			}
+			hash.Set(&String{Value: field.name}, val)
+		}
+		return hash, nil
+
+	case reflect.Interface:
+		if value.IsNil() {
+			return NULL, nil
+		}
+		return fromGoValue(value.Elem(), seen)
+
+	case reflect.Pointer:
+		if value.IsNil() {
+			return NULL, nil
+		}
+		if seen[value.Pointer()] {
+			return nil, cycleError(value)
+		}
+		seen[value.Pointer()] = true
+		defer delete(seen, value.Pointer())
+		return fromGoValue(value.Elem(), seen)
+	}
+
+	return nil, errors.New("cannot convert " + value.Type().String() + " to a kev object")
+}
+
+// toGoValue converts the kev object and stores
+// the result in the settable reflected Go value
+func toGoValue(obj Object, value reflect.Value) error {
+	if obj == nil {
+		obj = NULL
+	}
+	t := value.Type()
+
+	// an empty interface gets the
+	// closest matching native Go value
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		return toNativeValue(obj, value)
+	}
+
+	// objects are passed through untouched
+	// when the target asks for them
+	if reflect.TypeOf(obj).AssignableTo(t) {
+		value.Set(reflect.ValueOf(obj))
+		return nil
+	}
+
+	// null clears anything that can be nil
+	if _, ok := obj.(*Null); ok {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			value.Set(reflect.Zero(t))
+			return nil
+		}
+		return conversionError(obj, t)
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := obj.(*Integer)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		if value.OverflowInt(integer.Value) {
+			return fmt.Errorf("%d overflows %s", integer.Value, t)
+		}
+		value.SetInt(integer.Value)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		integer, ok := obj.(*Integer)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		if integer.Value < 0 || value.OverflowUint(uint64(integer.Value)) {
+			return fmt.Errorf("%d overflows %s", integer.Value, t)
+		}
+		value.SetUint(uint64(integer.Value))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		// both integers and floats
+		// can be stored in a float
+		float, ok := ToFloat(obj)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		value.SetFloat(float)
+		return nil
+
+	case reflect.String:
+		str, ok := obj.(*String)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		value.SetString(str.Value)
+		return nil
+
+	case reflect.Bool:
+		boolean, ok := obj.(*Boolean)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		value.SetBool(boolean.Value)
+		return nil
+
+	case reflect.Slice:
+		array, ok := obj.(*Array)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		slice := reflect.MakeSlice(t, len(array.Elements), len(array.Elements))
+		for idx, el := range array.Elements {
+			if err := toGoValue(el, slice.Index(idx)); err != nil {
+				return fmt.Errorf("element %d: %s", idx, err)
+			}
+		}
+		value.Set(slice)
+		return nil
+
+	case reflect.Array:
+		array, ok := obj.(*Array)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		if len(array.Elements) != t.Len() {
+			return fmt.Errorf("cannot convert ARRAY of length %d to %s", len(array.Elements), t)
+		}
+		for idx, el := range array.Elements {
+			if err := toGoValue(el, value.Index(idx)); err != nil {
+				return fmt.Errorf("element %d: %s", idx, err)
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		m := reflect.MakeMapWithSize(t, hash.Len())
+		for _, pair := range hash.Pairs() {
+			key := reflect.New(t.Key()).Elem()
+			if err := toGoValue(pair.Key, key); err != nil {
+				return fmt.Errorf("key %s: %s", pair.Key.Inspect(), err)
+			}
+			val := reflect.New(t.Elem()).Elem()
+			if err := toGoValue(pair.Value, val); err != nil {
+				return fmt.Errorf("value of key %s: %s", pair.Key.Inspect(), err)
+			}
+			m.SetMapIndex(key, val)
+		}
+		value.Set(m)
+		return nil
+
+	case reflect.Struct:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return conversionError(obj, t)
+		}
+		for _, field := range structFields(t) {
+			fieldValue, ok := hash.Get(&String{Value: field.name})
+			if !ok {
+				continue
+			}
+			if err := toGoValue(fieldValue, value.Field(field.index)); err != nil {
+				return fmt.Errorf("field %s: %s", field.name, err)
+			}
+		}
+		return nil
+
+	case reflect.Pointer:
+		elem := reflect.New(t.Elem())
+		if err := toGoValue(obj, elem.Elem()); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	return conversionError(obj, t)
+}
+
+// toNativeValue converts the kev object into the Go value
+// that matches it most closely and stores it in the interface
+func toNativeValue(obj Object, value reflect.Value) error {
+	var native reflect.Type
+	switch obj := obj.(type) {
+	case *Null:
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	case *Integer:
+		native = reflect.TypeOf(int64(0))
+	case *Float:
+		native = reflect.TypeOf(float64(0))
+	case *String:
+		native = reflect.TypeOf("")
+	case *Boolean:
+		native = reflect.TypeOf(false)
+	case *Array:
+		native = reflect.TypeOf([]interface{}{})
+	case *Hash:
+		native = reflect.TypeOf(map[string]interface{}{})
+		for _, pair := range obj.Pairs() {
+			if _, ok := pair.Key.(*String); !ok {
+				native = reflect.TypeOf(map[interface{}]interface{}{})
+				break
+			}
+		}
+	default:
+		return conversionError(obj, value.Type())
+	}
+
+	converted := reflect.New(native).Elem()
+	if err := toGoValue(obj, converted); err != nil {
+		return err
+	}
+	value.Set(converted)
+	return nil
+}
+
+// Represents a struct field that is
+// converted to and from a hash pair
+type structField struct {
+	index int
+	name  string
+}
+
+// structFields returns the exported fields of the struct
+// type along with the hash key each one is stored under
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("kev"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, structField{index: idx, name: name})
+	}
+	return fields
+}
+
+// lessMapKey orders Go map keys, numbers by
+// their value and everything else by its text
+func lessMapKey(a, b reflect.Value) bool {
+	switch {
+	case a.CanInt() && b.CanInt():
+		return a.Int() < b.Int()
+	case a.CanUint() && b.CanUint():
+		return a.Uint() < b.Uint()
+	default:
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	}
+}
+
+// checks if the value holds a nil pointer, map, slice, etc...
+func isNilValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return value.IsNil()
+	}
+	return false
+}
+
+// creates the error returned when a Go value
+// refers back to itself and can't be converted
+func cycleError(value reflect.Value) error {
+	return fmt.Errorf("cannot convert %s, it refers back to itself", value.Type())
+}
+
+// creates the error returned when an object
+// can't be converted into the given type
+func conversionError(obj Object, t reflect.Type) error {
+	return fmt.Errorf("cannot convert %s to %s", obj.Type(), t)
+}
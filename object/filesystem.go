@@ -0,0 +1,358 @@
+package object
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileSystem is everything kev does with files, like reading the
+// modules it imports or the files builtins such as readFile work on.
+// The program that embeds kev picks the file system, so it can
+// give scripts the real disk, a part of it, or no disk at all
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	AppendFile(name string, data []byte) error
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+	Remove(name string) error
+}
+
+// ErrReadOnly is returned when a read-only
+// file system is asked to change a file
+var ErrReadOnly = errors.New("file system is read-only")
+
+// ErrOutsideRoot is returned when a rooted file system
+// is asked for a file that isn't inside its root
+var ErrOutsideRoot = errors.New("path is outside of the root directory")
+
+// Returns the file system of the operating system.
+// Relative names are resolved against the working directory
+func OSFileSystem() FileSystem {
+	return osFileSystem{}
+}
+
+// Returns a file system that only lets files be read from fsys.
+// Writing, appending or removing returns ErrReadOnly
+func ReadOnlyFileSystem(fsys FileSystem) FileSystem {
+	return readOnlyFileSystem{fsys: fsys}
+}
+
+// Returns a file system that can't be used at all.
+// Every operation returns fs.ErrPermission
+func DenyFileSystem() FileSystem {
+	return denyFileSystem{}
+}
+
+// Returns a file system jailed to the directory, which is treated
+// as the root of the file system. Relative and absolute names are both
+// resolved inside the directory, and names that climb out of it with
+// .. return ErrOutsideRoot. Symbolic links are followed before every
+// operation, and names that lead out of the directory through a link
+// return ErrOutsideRoot too. remove deletes a link itself, not the
+// file it leads to
+func RootedFileSystem(dir string) FileSystem {
+	return rootedFileSystem{root: filepath.Clean(dir)}
+}
+
+// Returns a read-only file system that reads from fsys,
+// like an embed.FS or a fstest.MapFS. Names are resolved from the
+// root of fsys, so a leading slash and any ./ are ignored
+func FromFS(fsys fs.FS) FileSystem {
+	return ioFileSystem{fsys: fsys}
+}
+
+// osFileSystem works on the files of the operating system
+type osFileSystem struct{}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFileSystem) WriteFile(name string, data []byte) error {
+	return os.WriteFile(name, data, 0o644)
+}
+
+func (osFileSystem) AppendFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (osFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// readOnlyFileSystem passes reads through
+// to the file system it wraps and refuses writes
+type readOnlyFileSystem struct {
+	fsys FileSystem
+}
+
+func (r readOnlyFileSystem) ReadFile(name string) ([]byte, error) {
+	return r.fsys.ReadFile(name)
+}
+
+func (r readOnlyFileSystem) WriteFile(name string, data []byte) error {
+	return pathError("write", name, ErrReadOnly)
+}
+
+func (r readOnlyFileSystem) AppendFile(name string, data []byte) error {
+	return pathError("append", name, ErrReadOnly)
+}
+
+func (r readOnlyFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return r.fsys.ReadDir(name)
+}
+
+func (r readOnlyFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return r.fsys.Stat(name)
+}
+
+func (r readOnlyFileSystem) Remove(name string) error {
+	return pathError("remove", name, ErrReadOnly)
+}
+
+// denyFileSystem refuses everything
+type denyFileSystem struct{}
+
+func (denyFileSystem) ReadFile(name string) ([]byte, error) {
+	return nil, pathError("read", name, fs.ErrPermission)
+}
+
+func (denyFileSystem) WriteFile(name string, data []byte) error {
+	return pathError("write", name, fs.ErrPermission)
+}
+
+func (denyFileSystem) AppendFile(name string, data []byte) error {
+	return pathError("append", name, fs.ErrPermission)
+}
+
+func (denyFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return nil, pathError("readdir", name, fs.ErrPermission)
+}
+
+func (denyFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return nil, pathError("stat", name, fs.ErrPermission)
+}
+
+func (denyFileSystem) Remove(name string) error {
+	return pathError("remove", name, fs.ErrPermission)
+}
+
+// rootedFileSystem works on the files of the operating
+// system that are inside of its root directory
+type rootedFileSystem struct {
+	root string
+}
+
+// resolve returns the path on the operating system of the name,
+// which is looked up inside of the root directory. Symbolic links
+// in the path are followed, except for the last element when
+// followLast is false, and the path they lead to has to be
+// inside of the root directory too
+func (r rootedFileSystem) resolve(op, name string, followLast bool) (string, error) {
+	name = filepath.ToSlash(name)
+	if strings.HasPrefix(name, "/") {
+		name = name[1:]
+	}
+	name = path.Clean(name)
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return "", pathError(op, name, ErrOutsideRoot)
+	}
+
+	root, err := filepath.Abs(r.root)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", pathError(op, name, err)
+	}
+	joined := filepath.Join(root, filepath.FromSlash(name))
+	var resolved string
+	if followLast || joined == root {
+		resolved, err = followSymlinks(joined)
+	} else {
+		resolved, err = followSymlinks(filepath.Dir(joined))
+		resolved = filepath.Join(resolved, filepath.Base(joined))
+	}
+	if err != nil {
+		return "", pathError(op, name, err)
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", pathError(op, name, ErrOutsideRoot)
+	}
+	return resolved, nil
+}
+
+// maxSymlinks is how many links followSymlinks follows
+// before it gives up, so links that form a loop end
+const maxSymlinks = 255
+
+// followSymlinks returns the absolute path with every symbolic link
+// in it followed. Unlike filepath.EvalSymlinks the path doesn't have
+// to exist, so a file that is about to be written can be resolved.
+// The elements after the last one that exists are kept as they are,
+// and a link to a file that doesn't exist yet is still followed
+func followSymlinks(p string) (string, error) {
+	for links := 0; links < maxSymlinks; links++ {
+		// find the longest start of the path that exists
+		existing, missing := p, ""
+		resolved, err := filepath.EvalSymlinks(existing)
+		for err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+			parent := filepath.Dir(existing)
+			if parent == existing {
+				return p, nil
+			}
+			missing = filepath.Join(filepath.Base(existing), missing)
+			existing = parent
+			resolved, err = filepath.EvalSymlinks(existing)
+		}
+		if missing == "" {
+			return resolved, nil
+		}
+
+		// the first missing element is either really missing, so
+		// nothing after it can be a link, or a link to a missing file
+		first, rest, _ := strings.Cut(missing, string(filepath.Separator))
+		target, err := os.Readlink(filepath.Join(resolved, first))
+		if err != nil {
+			return filepath.Join(resolved, missing), nil
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(resolved, target)
+		}
+		p = filepath.Join(target, rest)
+	}
+	return "", errors.New("too many levels of symbolic links")
+}
+
+func (r rootedFileSystem) ReadFile(name string) ([]byte, error) {
+	resolved, err := r.resolve("read", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return osFileSystem{}.ReadFile(resolved)
+}
+
+func (r rootedFileSystem) WriteFile(name string, data []byte) error {
+	resolved, err := r.resolve("write", name, true)
+	if err != nil {
+		return err
+	}
+	return osFileSystem{}.WriteFile(resolved, data)
+}
+
+func (r rootedFileSystem) AppendFile(name string, data []byte) error {
+	resolved, err := r.resolve("append", name, true)
+	if err != nil {
+		return err
+	}
+	return osFileSystem{}.AppendFile(resolved, data)
+}
+
+func (r rootedFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	resolved, err := r.resolve("readdir", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return osFileSystem{}.ReadDir(resolved)
+}
+
+func (r rootedFileSystem) Stat(name string) (fs.FileInfo, error) {
+	resolved, err := r.resolve("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return osFileSystem{}.Stat(resolved)
+}
+
+func (r rootedFileSystem) Remove(name string) error {
+	resolved, err := r.resolve("remove", name, false)
+	if err != nil {
+		return err
+	}
+	return osFileSystem{}.Remove(resolved)
+}
+
+// ioFileSystem reads from an fs.FS
+type ioFileSystem struct {
+	fsys fs.FS
+}
+
+// resolve turns the name into a name fs.FS accepts
+func (i ioFileSystem) resolve(op, name string) (string, error) {
+	name = path.Clean("/" + filepath.ToSlash(name))[1:]
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return "", pathError(op, name, fs.ErrInvalid)
+	}
+	return name, nil
+}
+
+func (i ioFileSystem) ReadFile(name string) ([]byte, error) {
+	resolved, err := i.resolve("read", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(i.fsys, resolved)
+}
+
+func (i ioFileSystem) WriteFile(name string, data []byte) error {
+	return pathError("write", name, ErrReadOnly)
+}
+
+func (i ioFileSystem) AppendFile(name string, data []byte) error {
+	return pathError("append", name, ErrReadOnly)
+}
+
+func (i ioFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	resolved, err := i.resolve("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(i.fsys, resolved)
+}
+
+func (i ioFileSystem) Stat(name string) (fs.FileInfo, error) {
+	resolved, err := i.resolve("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(i.fsys, resolved)
+}
+
+func (i ioFileSystem) Remove(name string) error {
+	return pathError("remove", name, ErrReadOnly)
+}
+
+// pathError wraps the error with the
+// operation and the name it failed on
+func pathError(op, name string, err error) error {
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
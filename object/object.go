@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/Gage-McGuire/kev/ast"
@@ -13,6 +15,7 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	STRING_OBJ       = "STRING"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
@@ -22,8 +25,32 @@ const (
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
 	HASH_OBJ         = "HASH"
+	MODULE_OBJ       = "MODULE"
 )
 
+var (
+	// NULL is the singleton Null object
+	// every null value in kev points to
+	NULL = &Null{}
+
+	// TRUE is the singleton Boolean object
+	// every true value in kev points to
+	TRUE = &Boolean{Value: true}
+
+	// FALSE is the singleton Boolean object
+	// every false value in kev points to
+	FALSE = &Boolean{Value: false}
+)
+
+// Returns the TRUE or FALSE singleton
+// matching the native Go boolean
+func NativeBool(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
+
 // Base representation of an object.
 // It holds the type of the object,
 // and a string representation of the value of the object
@@ -40,6 +67,11 @@ type Integer struct {
 	Value int64
 }
 
+// Represents a float object
+type Float struct {
+	Value float64
+}
+
 // Represents a string object
 type String struct {
 	Value string
@@ -58,9 +90,13 @@ type ReturnValue struct {
 	Value Object
 }
 
-// Represents an error object
+// Represents an error object. The exit builtin stops the
+// program with an error too, which has Exit set and holds
+// the status the program exits with in Code
 type Error struct {
 	Message string
+	Exit    bool
+	Code    int
 }
 
 type Array struct {
@@ -74,6 +110,14 @@ type Function struct {
 	Env        *Environment
 }
 
+// Represents a module object, which holds
+// the exported bindings of an imported kev file
+type Module struct {
+	Name    string            // the file name of the module
+	Path    string            // the path the module was loaded from
+	Exports map[string]Object // the exported bindings, by name
+}
+
 type HashPair struct {
 	Key   Object
 	Value Object
@@ -84,14 +128,77 @@ type HashKey struct {
 	Value uint64
 }
 
+// Represents a hash object.
+// The pairs are kept in the order their keys were first set,
+// so inspecting or iterating over a hash always gives the same
+// order, while the index map keeps lookups O(1). Keys that share
+// a HashKey end up in the same bucket of the index map and are
+// told apart by comparing the keys themselves
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	pairs []HashPair
+	index map[HashKey][]int // positions in pairs of the keys with each HashKey
 }
 
 type Hashable interface {
 	HashKey() HashKey
 }
 
+/*
+ * Host object interfaces
+ *
+ * Objects defined by a Go host program can implement any of
+ * the following interfaces to take part in the evaluator's
+ * operators the same way the built in objects do
+ */
+
+// Indexable is implemented by objects that
+// support the index operator, e.g. <object>[<index>]
+type Indexable interface {
+	Index(index Object) Object
+}
+
+// Callable is implemented by objects that can be
+// called like a function, e.g. <object>(<args>)
+type Callable interface {
+	Call(ctx *Context, args ...Object) Object
+}
+
+// Comparable is implemented by objects that support the
+// ==, !=, < and > operators. Compare returns a negative number
+// when the object is less than other, zero when they are equal
+// and a positive number when it is greater. It returns false
+// when the object can't be compared with other. When the object
+// is on the right of the operator and the left object can't
+// compare them, the result of Compare is reversed instead
+type Comparable interface {
+	Compare(other Object) (int, bool)
+}
+
+// Operable is implemented by objects that define their
+// own infix operators, e.g. <object> + <right>. It returns
+// false when the object doesn't support the operator
+// with the given right object
+type Operable interface {
+	InfixOperator(operator string, right Object) (Object, bool)
+}
+
+// ReflectedOperable is implemented by objects that define
+// infix operators where they are the right object, e.g.
+// <left> * <object>. It's only tried when the left object
+// doesn't support the operator itself, and returns false
+// when the object doesn't support the operator with the
+// given left object
+type ReflectedOperable interface {
+	ReflectedInfixOperator(operator string, left Object) (Object, bool)
+}
+
+// Iterable is implemented by objects that hold a sequence
+// of objects. Iterate calls yield with each object in order
+// and stops early when yield returns false
+type Iterable interface {
+	Iterate(yield func(Object) bool)
+}
+
 func (b *Boolean) HashKey() HashKey {
 	var value uint64
 	if b.Value {
@@ -106,6 +213,15 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+// A float with no fraction hashes like the integer it's
+// equal to, so {1: "a"}[1.0] finds the pair of 1
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && f.Value >= -(1<<63) && f.Value < 1<<63 {
+		return HashKey{Type: INTEGER_OBJ, Value: uint64(int64(f.Value))}
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(s.Value))
@@ -145,6 +261,22 @@ func (i *Integer) Type() ObjectType {
 	return INTEGER_OBJ
 }
 
+// Returns the value of the float object. Whole
+// numbers keep a trailing .0 so they don't read
+// like integers, e.g. 2.0 instead of 2
+func (f *Float) Inspect() string {
+	str := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(str, ".eIN") {
+		str += ".0"
+	}
+	return str
+}
+
+// Returns the type of the float object
+func (f *Float) Type() ObjectType {
+	return FLOAT_OBJ
+}
+
 // Returns the value of the string object
 func (s *String) Inspect() string {
 	return s.Value
@@ -215,10 +347,12 @@ func (a *Array) Type() ObjectType {
 	return ARRAY_OBJ
 }
 
+// Returns the value of the hash object
+// with the pairs in insertion order
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.pairs {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
 	}
 	out.WriteString("{")
@@ -227,27 +361,179 @@ func (h *Hash) Inspect() string {
 	return out.String()
 }
 
+// Returns the type of the hash object
 func (h *Hash) Type() ObjectType {
 	return HASH_OBJ
 }
 
+// Creates a new empty hash
+func NewHash() *Hash {
+	return &Hash{index: make(map[HashKey][]int)}
+}
+
+// Sets the value of the given key. A new key is added after
+// every existing one, while an existing key keeps its place.
+// It returns false if the key doesn't implement Hashable
+func (h *Hash) Set(key, value Object) bool {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return false
+	}
+	if h.index == nil {
+		h.index = make(map[HashKey][]int)
+	}
+	hashKey := hashable.HashKey()
+	if idx, ok := h.find(hashKey, key); ok {
+		h.pairs[idx] = HashPair{Key: key, Value: value}
+		return true
+	}
+	h.index[hashKey] = append(h.index[hashKey], len(h.pairs))
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+	return true
+}
+
+// Returns the value of the given key
+// and whether the hash holds the key
+func (h *Hash) Get(key Object) (Object, bool) {
+	pair, ok := h.GetPair(key)
+	return pair.Value, ok
+}
+
+// Returns the pair of the given key
+// and whether the hash holds the key
+func (h *Hash) GetPair(key Object) (HashPair, bool) {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return HashPair{}, false
+	}
+	idx, ok := h.find(hashable.HashKey(), key)
+	if !ok {
+		return HashPair{}, false
+	}
+	return h.pairs[idx], true
+}
+
+// Removes the pair of the given key, keeping
+// the order of the pairs that are left
+func (h *Hash) Delete(key Object) {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return
+	}
+	hashKey := hashable.HashKey()
+	idx, ok := h.find(hashKey, key)
+	if !ok {
+		return
+	}
+
+	// take the pair out of its bucket
+	bucket := []int{}
+	for _, pairIdx := range h.index[hashKey] {
+		if pairIdx != idx {
+			bucket = append(bucket, pairIdx)
+		}
+	}
+	if len(bucket) == 0 {
+		delete(h.index, hashKey)
+	} else {
+		h.index[hashKey] = bucket
+	}
+
+	// every pair after it moves down one position
+	h.pairs = append(h.pairs[:idx], h.pairs[idx+1:]...)
+	for _, bucket := range h.index {
+		for bucketIdx, pairIdx := range bucket {
+			if pairIdx > idx {
+				bucket[bucketIdx] = pairIdx - 1
+			}
+		}
+	}
+}
+
+// Returns the number of pairs in the hash
+func (h *Hash) Len() int {
+	return len(h.pairs)
+}
+
+// Returns the pairs of the hash in insertion order.
+// The returned slice must not be modified
+func (h *Hash) Pairs() []HashPair {
+	return h.pairs
+}
+
+// Returns a copy of the hash that
+// can be changed without touching the original
+func (h *Hash) Copy() *Hash {
+	hash := &Hash{
+		pairs: make([]HashPair, len(h.pairs)),
+		index: make(map[HashKey][]int, len(h.index)),
+	}
+	copy(hash.pairs, h.pairs)
+	for hashKey, bucket := range h.index {
+		hash.index[hashKey] = append([]int{}, bucket...)
+	}
+	return hash
+}
+
+// find returns the position of the key's pair. Different keys
+// can share a HashKey, so every pair in the HashKey's bucket
+// has its actual key compared with the one being looked for
+func (h *Hash) find(hashKey HashKey, key Object) (int, bool) {
+	for _, idx := range h.index[hashKey] {
+		if Equal(h.pairs[idx].Key, key) {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// Returns the exported binding with the given name
+func (m *Module) Get(name string) (Object, bool) {
+	obj, ok := m.Exports[name]
+	return obj, ok
+}
+
+// Returns the exported binding named by the
+// string index, so lib["name"] works like lib.name
+func (m *Module) Index(index Object) Object {
+	name, ok := index.(*String)
+	if !ok {
+		return &Error{Message: fmt.Sprintf("module index must be STRING, got %s", index.Type())}
+	}
+	obj, ok := m.Get(name.Value)
+	if !ok {
+		return &Error{Message: fmt.Sprintf("module %s has no export %s", m.Name, name.Value)}
+	}
+	return obj
+}
+
+// Returns the string representation of the module object
+func (m *Module) Inspect() string {
+	return "module(" + m.Name + ")"
+}
+
+// Returns the type of the module object
+// which is always a MODULE_OBJ
+func (m *Module) Type() ObjectType {
+	return MODULE_OBJ
+}
+
 /*
  * Environment
  */
 
-// Creates a new environment
-// with an empty store
+// Creates a new environment with an empty store
+// and a context of its own from NewContext
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	return &Environment{store: s, outer: nil, ctx: NewContext()}
 }
 
-// Creates a new environment
-// which is enclosed and limited to its block statement
+// Creates a new environment which is enclosed and limited
+// to its block statement. It shares the context of outer
 func NewEnclosedEnvironment(outer *Environment) *Environment {
-	env := NewEnvironment()
-	env.outer = outer
-	return env
+	s := make(map[string]Object)
+	return &Environment{store: s, outer: outer}
 }
 
 // Represents an environment with a store
@@ -255,6 +541,8 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 type Environment struct {
 	store map[string]Object
 	outer *Environment
+	ctx   *Context
+	file  string // the file the code using the environment came from
 }
 
 // Returns the object with the given name
@@ -274,12 +562,53 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// Returns the context builtin functions are called with.
+// Enclosed environments share the context of their outer
+// environment. An environment that has neither gets a new
+// context of its own, so no two programs share a context
+func (e *Environment) Context() *Context {
+	if e.ctx != nil {
+		return e.ctx
+	}
+	if e.outer != nil {
+		return e.outer.Context()
+	}
+	e.ctx = NewContext()
+	return e.ctx
+}
+
+// Sets the context builtin functions are called with
+func (e *Environment) SetContext(ctx *Context) {
+	e.ctx = ctx
+}
+
+// Returns the path of the file the code using the environment
+// came from, which imports are resolved relative to. Enclosed
+// environments share the file of their outer environment, and
+// it's empty for code that didn't come from a file
+func (e *Environment) File() string {
+	if e.file != "" {
+		return e.file
+	}
+	if e.outer != nil {
+		return e.outer.File()
+	}
+	return ""
+}
+
+// Sets the path of the file the code
+// using the environment came from
+func (e *Environment) SetFile(path string) {
+	e.file = path
+}
+
 /*
  * Built-in functions
  */
 
-// Represents a built-in function
-type BuiltinFunction func(args ...Object) Object
+// Represents a built-in function.
+// It's called with the context of the environment it was called from
+type BuiltinFunction func(ctx *Context, args ...Object) Object
 
 // Represents a built-in function object
 type Builtin struct {
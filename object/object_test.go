@@ -1,6 +1,14 @@
 package object
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -20,3 +28,559 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+type convertAddress struct {
+	City string `kev:"city"`
+	Zip  int    `kev:"zip"`
+}
+
+type convertNode struct {
+	Next *convertNode
+}
+
+type convertPerson struct {
+	Name     string            `kev:"name"`
+	Age      int64             `kev:"age"`
+	Admin    bool              `kev:"admin"`
+	Tags     []string          `kev:"tags"`
+	Scores   map[string]int    `kev:"scores"`
+	Address  *convertAddress   `kev:"address"`
+	Extra    map[string]string `kev:"extra"`
+	Nickname string
+	Secret   string `kev:"-"`
+	internal string
+}
+
+func TestFromGo(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{5, "5"},
+		{int8(-3), "-3"},
+		{uint16(7), "7"},
+		{"hello", "hello"},
+		{true, "true"},
+		{2.5, "2.5"},
+		{float32(3), "3.0"},
+		{[]int{1, 2, 3}, "[1, 2, 3]"},
+		{[2]string{"a", "b"}, "[a, b]"},
+		{[]interface{}{1, "two", false, nil}, "[1, two, false, null]"},
+		{map[string]int{"one": 1}, "{one: 1}"},
+		{map[string]int{"b": 2, "a": 1, "c": 3}, "{a: 1, b: 2, c: 3}"},
+		{map[int]string{10: "ten", 2: "two", -1: "minus one"}, "{-1: minus one, 2: two, 10: ten}"},
+		{&convertAddress{City: "Paris", Zip: 75000}, "{city: Paris, zip: 75000}"},
+		{(*convertAddress)(nil), "null"},
+		{&Integer{Value: 9}, "9"},
+	}
+
+	for _, tt := range tests {
+		obj, err := FromGo(tt.input)
+		if err != nil {
+			t.Errorf("FromGo(%#v) returned error: %s", tt.input, err)
+			continue
+		}
+		if obj.Inspect() != tt.expected {
+			t.Errorf("FromGo(%#v) wrong. got=%s, want=%s", tt.input, obj.Inspect(), tt.expected)
+		}
+	}
+
+	if obj, _ := FromGo(true); obj != TRUE {
+		t.Errorf("FromGo(true) did not return the TRUE singleton")
+	}
+	if _, err := FromGo(uint64(1 << 63)); err == nil {
+		t.Errorf("FromGo did not return an error for an overflowing uint64")
+	}
+	if _, err := FromGo(make(chan int)); err == nil {
+		t.Errorf("FromGo did not return an error for a channel")
+	}
+
+	loop := &convertNode{}
+	loop.Next = &convertNode{Next: loop}
+	if _, err := FromGo(loop); err == nil || err.Error() != "field Next: field Next: cannot convert *object.convertNode, it refers back to itself" {
+		t.Errorf("FromGo did not return an error for a cycle. got=%v", err)
+	}
+	self := map[string]interface{}{}
+	self["self"] = self
+	if _, err := FromGo(self); err == nil {
+		t.Errorf("FromGo did not return an error for a map holding itself")
+	}
+	shared := &convertAddress{City: "Rome"}
+	if obj, err := FromGo([]*convertAddress{shared, shared}); err != nil || obj.Inspect() != "[{city: Rome, zip: 0}, {city: Rome, zip: 0}]" {
+		t.Errorf("FromGo of a pointer used twice wrong. got=%v, err=%v", obj, err)
+	}
+}
+
+func TestFromGoStruct(t *testing.T) {
+	obj, err := FromGo(convertPerson{Name: "Ada", Age: 36, Secret: "shh", Nickname: "ada"})
+	if err != nil {
+		t.Fatalf("FromGo returned error: %s", err)
+	}
+	hash, ok := obj.(*Hash)
+	if !ok {
+		t.Fatalf("object is not Hash. got=%T (%+v)", obj, obj)
+	}
+
+	expected := map[string]string{
+		"name":     "Ada",
+		"age":      "36",
+		"admin":    "false",
+		"tags":     "null",
+		"address":  "null",
+		"Nickname": "ada",
+	}
+	for key, value := range expected {
+		pairValue, ok := hash.Get(&String{Value: key})
+		if !ok {
+			t.Errorf("no pair for key %q", key)
+			continue
+		}
+		if pairValue.Inspect() != value {
+			t.Errorf("pair %q has wrong value. got=%s, want=%s", key, pairValue.Inspect(), value)
+		}
+	}
+	for _, key := range []string{"Secret", "-", "internal"} {
+		if _, ok := hash.Get(&String{Value: key}); ok {
+			t.Errorf("pair for skipped field %q exists", key)
+		}
+	}
+}
+
+func TestToGo(t *testing.T) {
+	var i int
+	if err := ToGo(&Integer{Value: 5}, &i); err != nil || i != 5 {
+		t.Errorf("ToGo into int wrong. got=%d, err=%v", i, err)
+	}
+
+	var s string
+	if err := ToGo(&String{Value: "hi"}, &s); err != nil || s != "hi" {
+		t.Errorf("ToGo into string wrong. got=%q, err=%v", s, err)
+	}
+
+	var f float64
+	if err := ToGo(&Float{Value: 1.5}, &f); err != nil || f != 1.5 {
+		t.Errorf("ToGo into float64 wrong. got=%f, err=%v", f, err)
+	}
+	if err := ToGo(&Integer{Value: 3}, &f); err != nil || f != 3 {
+		t.Errorf("ToGo integer into float64 wrong. got=%f, err=%v", f, err)
+	}
+
+	var native interface{}
+	if err := ToGo(&Array{Elements: []Object{&Integer{Value: 1}, TRUE, NULL}}, &native); err != nil {
+		t.Fatalf("ToGo into interface returned error: %s", err)
+	}
+	if fmt.Sprintf("%#v", native) != "[]interface {}{1, true, interface {}(nil)}" {
+		t.Errorf("ToGo into interface wrong. got=%#v", native)
+	}
+
+	var obj Object
+	if err := ToGo(TRUE, &obj); err != nil || obj != TRUE {
+		t.Errorf("ToGo into Object wrong. got=%v, err=%v", obj, err)
+	}
+
+	keyed := NewHash()
+	keyed.Set(&Integer{Value: 1}, &String{Value: "one"})
+	keyed.Set(TRUE, &String{Value: "yes"})
+	native = nil
+	if err := ToGo(keyed, &native); err != nil {
+		t.Fatalf("ToGo of a hash with non-string keys returned error: %s", err)
+	}
+	if !reflect.DeepEqual(native, map[interface{}]interface{}{int64(1): "one", true: "yes"}) {
+		t.Errorf("ToGo of a hash with non-string keys wrong. got=%#v", native)
+	}
+
+	i = 5
+	if err := ToGo(nil, &i); err == nil || err.Error() != "cannot convert NULL to int" {
+		t.Errorf("ToGo of nil into int wrong. got=%v", err)
+	}
+	native = 1
+	if err := ToGo(nil, &native); err != nil || native != nil {
+		t.Errorf("ToGo of nil into interface wrong. got=%#v, err=%v", native, err)
+	}
+
+	errorTests := []struct {
+		obj      Object
+		target   interface{}
+		expected string
+	}{
+		{&String{Value: "5"}, new(int), "cannot convert STRING to int"},
+		{&Integer{Value: 300}, new(uint8), "300 overflows uint8"},
+		{&Integer{Value: -1}, new(uint), "-1 overflows uint"},
+		{NULL, new(bool), "cannot convert NULL to bool"},
+		{&Float{Value: 1.5}, new(int), "cannot convert FLOAT to int"},
+		{&Array{Elements: []Object{&String{Value: "x"}}}, new([]int), "element 0: cannot convert STRING to int"},
+		{&Integer{Value: 1}, 5, "cannot convert into int, want a non-nil pointer"},
+	}
+	for _, tt := range errorTests {
+		err := ToGo(tt.obj, tt.target)
+		if err == nil {
+			t.Errorf("ToGo(%s, %T) did not return an error", tt.obj.Inspect(), tt.target)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestGoRoundTrip(t *testing.T) {
+	original := convertPerson{
+		Name:     "Ada",
+		Age:      36,
+		Admin:    true,
+		Tags:     []string{"math", "engines"},
+		Scores:   map[string]int{"chess": 3, "go": 9},
+		Address:  &convertAddress{City: "London", Zip: 1815},
+		Nickname: "countess",
+	}
+
+	obj, err := FromGo(original)
+	if err != nil {
+		t.Fatalf("FromGo returned error: %s", err)
+	}
+
+	var decoded convertPerson
+	if err := ToGo(obj, &decoded); err != nil {
+		t.Fatalf("ToGo returned error: %s", err)
+	}
+	if !reflect.DeepEqual(original, decoded) {
+		t.Errorf("round trip wrong.\noriginal=%#v\ndecoded=%#v", original, decoded)
+	}
+
+	values := []interface{}{
+		int64(-12),
+		"text",
+		false,
+		[]int64{4, 5, 6},
+		map[string]bool{"yes": true, "no": false},
+		map[int64]string{1: "one", 2: "two"},
+		[][]string{{"a"}, {"b", "c"}},
+	}
+	for _, value := range values {
+		obj, err := FromGo(value)
+		if err != nil {
+			t.Errorf("FromGo(%#v) returned error: %s", value, err)
+			continue
+		}
+		decoded := reflect.New(reflect.TypeOf(value))
+		if err := ToGo(obj, decoded.Interface()); err != nil {
+			t.Errorf("ToGo(%s) returned error: %s", obj.Inspect(), err)
+			continue
+		}
+		if !reflect.DeepEqual(value, decoded.Elem().Interface()) {
+			t.Errorf("round trip wrong. original=%#v, decoded=%#v", value, decoded.Elem().Interface())
+		}
+	}
+}
+
+func TestHashOrder(t *testing.T) {
+	hash := NewHash()
+	hash.Set(&String{Value: "b"}, &Integer{Value: 1})
+	hash.Set(&String{Value: "a"}, &Integer{Value: 2})
+	hash.Set(&Integer{Value: 3}, &Integer{Value: 3})
+	hash.Set(&String{Value: "b"}, &Integer{Value: 4})
+
+	if hash.Inspect() != "{b: 4, a: 2, 3: 3}" {
+		t.Errorf("hash has wrong order. got=%s", hash.Inspect())
+	}
+
+	copied := hash.Copy()
+	hash.Delete(&String{Value: "b"})
+	if hash.Inspect() != "{a: 2, 3: 3}" {
+		t.Errorf("hash has wrong order after delete. got=%s", hash.Inspect())
+	}
+	if value, ok := hash.Get(&Integer{Value: 3}); !ok || value.Inspect() != "3" {
+		t.Errorf("lookup after delete wrong. got=%v, ok=%t", value, ok)
+	}
+	if copied.Inspect() != "{b: 4, a: 2, 3: 3}" {
+		t.Errorf("deleting from the hash changed its copy. got=%s", copied.Inspect())
+	}
+	if hash.Set(&Array{}, NULL) {
+		t.Errorf("hash accepted an unhashable key")
+	}
+	if hash.Len() != 2 {
+		t.Errorf("hash has wrong length. got=%d", hash.Len())
+	}
+}
+
+// collidingKey is a hashable object whose keys all share
+// the HashKey of the string "first", forcing hash collisions
+type collidingKey struct {
+	name string
+}
+
+func (c *collidingKey) Type() ObjectType { return "COLLIDING" }
+func (c *collidingKey) Inspect() string  { return c.name }
+func (c *collidingKey) HashKey() HashKey { return (&String{Value: "first"}).HashKey() }
+
+func TestHashKeyCollisions(t *testing.T) {
+	first := &collidingKey{name: "first"}
+	second := &collidingKey{name: "second"}
+	if first.HashKey() != second.HashKey() {
+		t.Fatalf("keys don't collide")
+	}
+
+	hash := NewHash()
+	hash.Set(first, &Integer{Value: 1})
+	hash.Set(second, &Integer{Value: 2})
+
+	if hash.Len() != 2 {
+		t.Fatalf("colliding keys overwrote each other. got=%s", hash.Inspect())
+	}
+	if value, ok := hash.Get(first); !ok || value.Inspect() != "1" {
+		t.Errorf("wrong value for first key. got=%v, ok=%t", value, ok)
+	}
+	if value, ok := hash.Get(second); !ok || value.Inspect() != "2" {
+		t.Errorf("wrong value for second key. got=%v, ok=%t", value, ok)
+	}
+	if _, ok := hash.Get(&collidingKey{name: "third"}); ok {
+		t.Errorf("found a pair for a key that was never set")
+	}
+
+	// the string has the same HashKey as the colliding
+	// keys, but it must not be mixed up with them
+	str := &String{Value: "first"}
+	if str.HashKey() != first.HashKey() {
+		t.Fatalf("string doesn't collide with the keys")
+	}
+	hash.Set(str, &Integer{Value: 3})
+	if hash.Len() != 3 {
+		t.Fatalf("string overwrote a colliding key. got=%s", hash.Inspect())
+	}
+
+	hash.Set(second, &Integer{Value: 4})
+	hash.Delete(first)
+	if hash.Inspect() != "{second: 4, first: 3}" {
+		t.Errorf("wrong pairs after update and delete. got=%s", hash.Inspect())
+	}
+	if value, ok := hash.Get(second); !ok || value.Inspect() != "4" {
+		t.Errorf("wrong value for second key after delete. got=%v, ok=%t", value, ok)
+	}
+	if _, ok := hash.Get(first); ok {
+		t.Errorf("found a pair for a deleted key")
+	}
+}
+
+func TestEqualAndCompare(t *testing.T) {
+	nested := func(values ...int64) *Array {
+		elements := []Object{}
+		for _, v := range values {
+			elements = append(elements, &Array{Elements: []Object{&Integer{Value: v}}})
+		}
+		return &Array{Elements: elements}
+	}
+	hash := func(pairs ...Object) *Hash {
+		h := NewHash()
+		for idx := 0; idx < len(pairs); idx += 2 {
+			h.Set(pairs[idx], pairs[idx+1])
+		}
+		return h
+	}
+	a, b := &String{Value: "a"}, &String{Value: "b"}
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+
+	equalTests := []struct {
+		left, right Object
+		expected    bool
+	}{
+		{one, &Integer{Value: 1}, true},
+		{one, &Float{Value: 1}, true},
+		{&Float{Value: 1.5}, &Float{Value: 1.5}, true},
+		{&Float{Value: 0}, &Float{Value: math.Copysign(0, -1)}, true},
+		{&Integer{Value: 1<<53 + 1}, &Float{Value: 1 << 53}, false},
+		{&Integer{Value: 1<<63 - 1}, &Float{Value: 1 << 63}, false},
+		{&Float{Value: math.NaN()}, &Float{Value: math.NaN()}, false},
+		{one, two, false},
+		{one, a, false},
+		{NULL, NULL, true},
+		{nested(1, 2), nested(1, 2), true},
+		{nested(1, 2), nested(2, 1), false},
+		{hash(a, one, b, two), hash(b, two, a, one), true},
+		{hash(a, nested(1)), hash(a, nested(1)), true},
+		{hash(a, one), hash(a, two), false},
+		{hash(a, one), hash(b, one), false},
+	}
+	for _, tt := range equalTests {
+		if got := Equal(tt.left, tt.right); got != tt.expected {
+			t.Errorf("Equal(%s, %s) wrong. got=%t, want=%t", tt.left.Inspect(), tt.right.Inspect(), got, tt.expected)
+		}
+	}
+
+	compareTests := []struct {
+		left, right Object
+		expected    int
+		ok          bool
+	}{
+		{one, two, -1, true},
+		{two, one, 1, true},
+		{one, &Float{Value: 1.5}, -1, true},
+		{&Float{Value: 2.5}, two, 1, true},
+		{&Integer{Value: 1<<53 + 1}, &Float{Value: 1 << 53}, 1, true},
+		{&Integer{Value: -1 << 63}, &Float{Value: -1 << 63}, 0, true},
+		{&Integer{Value: 1<<63 - 1}, &Float{Value: math.Inf(1)}, -1, true},
+		{&Float{Value: -0.5}, &Integer{Value: 0}, -1, true},
+		{&Float{Value: 0.5}, &Integer{Value: 0}, 1, true},
+		{one, &Float{Value: math.NaN()}, 0, false},
+		{a, b, -1, true},
+		{FALSE, TRUE, -1, true},
+		{TRUE, TRUE, 0, true},
+		{nested(1, 2), nested(1, 3), -1, true},
+		{nested(1, 2), nested(1), 1, true},
+		{nested(1), nested(1), 0, true},
+		{one, a, 0, false},
+		{NULL, NULL, 0, false},
+		{hash(a, one), hash(a, one), 0, false},
+		{&Array{Elements: []Object{one}}, &Array{Elements: []Object{a}}, 0, false},
+	}
+	for _, tt := range compareTests {
+		cmp, ok := Compare(tt.left, tt.right)
+		if ok != tt.ok {
+			t.Errorf("Compare(%s, %s) ok wrong. got=%t, want=%t", tt.left.Inspect(), tt.right.Inspect(), ok, tt.ok)
+			continue
+		}
+		if (cmp < 0) != (tt.expected < 0) || (cmp > 0) != (tt.expected > 0) {
+			t.Errorf("Compare(%s, %s) wrong. got=%d, want=%d", tt.left.Inspect(), tt.right.Inspect(), cmp, tt.expected)
+		}
+	}
+}
+
+func TestEnvironmentContext(t *testing.T) {
+	first := NewEnvironment()
+	second := NewEnvironment()
+	if first.Context() == second.Context() {
+		t.Fatalf("environments share a context")
+	}
+
+	first.Context().SetModule("a.kev", &Module{Name: "a"})
+	if _, ok := second.Context().Module("a.kev"); ok {
+		t.Errorf("module imported in one environment is cached in another")
+	}
+
+	inner := NewEnclosedEnvironment(NewEnclosedEnvironment(first))
+	if inner.Context() != first.Context() {
+		t.Errorf("enclosed environment does not share the context of its outer environment")
+	}
+
+	ctx := NewContext()
+	first.SetContext(ctx)
+	if inner.Context() != ctx {
+		t.Errorf("enclosed environment does not see the context set on its outer environment")
+	}
+}
+
+func TestHashKeyAgreesWithEqual(t *testing.T) {
+	numbers := []Object{
+		&Integer{Value: 0},
+		&Integer{Value: 1},
+		&Integer{Value: -7},
+		&Integer{Value: 1 << 53},
+		&Integer{Value: 1<<53 + 1},
+		&Integer{Value: -1 << 63},
+		&Float{Value: 0},
+		&Float{Value: math.Copysign(0, -1)},
+		&Float{Value: 1},
+		&Float{Value: 1.5},
+		&Float{Value: -7},
+		&Float{Value: 1 << 53},
+		&Float{Value: -1 << 63},
+		&Float{Value: 1 << 63},
+		&Float{Value: math.Inf(-1)},
+	}
+	for _, a := range numbers {
+		for _, b := range numbers {
+			if Equal(a, b) && a.(Hashable).HashKey() != b.(Hashable).HashKey() {
+				t.Errorf("%s %s and %s %s are equal but hash differently", a.Type(), a.Inspect(), b.Type(), b.Inspect())
+			}
+		}
+	}
+
+	hash := NewHash()
+	hash.Set(&Integer{Value: 1}, &String{Value: "a"})
+	hash.Set(&Float{Value: 2}, &String{Value: "b"})
+	for _, tt := range []struct {
+		key      Object
+		expected string
+	}{
+		{&Float{Value: 1}, "a"},
+		{&Integer{Value: 2}, "b"},
+	} {
+		value, ok := hash.Get(tt.key)
+		if !ok || value.Inspect() != tt.expected {
+			t.Errorf("hash.Get(%s) wrong. got=%v, want=%s", tt.key.Inspect(), value, tt.expected)
+		}
+	}
+	if _, ok := hash.Get(&Float{Value: 1.5}); ok {
+		t.Errorf("hash.Get(1.5) found a pair")
+	}
+}
+
+func TestRootedFileSystemSymlinks(t *testing.T) {
+	outside := t.TempDir()
+	root := t.TempDir()
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := func(target, name string) {
+		t.Helper()
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("symbolic links are not supported: %s", err)
+		}
+	}
+	write(filepath.Join(outside, "secret.txt"), "secret")
+	write(filepath.Join(root, "a.txt"), "a")
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link(outside, "out")
+	link(filepath.Join(outside, "secret.txt"), "secret.txt")
+	link(filepath.Join(outside, "new.txt"), "dangling.txt")
+	link("a.txt", "alias.txt")
+	link("../sub", "sub/self")
+	link("loop", "loop")
+
+	fsys := RootedFileSystem(root)
+	outsideTests := []struct {
+		name string
+		err  error
+	}{
+		{"secret.txt", func() error { _, err := fsys.ReadFile("secret.txt"); return err }()},
+		{"out/secret.txt", func() error { _, err := fsys.ReadFile("out/secret.txt"); return err }()},
+		{"out", func() error { _, err := fsys.ReadDir("out"); return err }()},
+		{"out/secret.txt", func() error { _, err := fsys.Stat("out/secret.txt"); return err }()},
+		{"out/x.txt", fsys.WriteFile("out/x.txt", []byte("x"))},
+		{"dangling.txt", fsys.WriteFile("dangling.txt", []byte("x"))},
+		{"out/secret.txt", fsys.AppendFile("out/secret.txt", []byte("x"))},
+		{"out/secret.txt", fsys.Remove("out/secret.txt")},
+	}
+	for _, tt := range outsideTests {
+		if !errors.Is(tt.err, ErrOutsideRoot) {
+			t.Errorf("%s: expected ErrOutsideRoot, got=%v", tt.name, tt.err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new.txt")); err == nil {
+		t.Errorf("writing through a dangling link created a file outside of the root")
+	}
+	if data, _ := os.ReadFile(filepath.Join(outside, "secret.txt")); string(data) != "secret" {
+		t.Errorf("file outside of the root was changed. got=%q", data)
+	}
+
+	if data, err := fsys.ReadFile("alias.txt"); err != nil || string(data) != "a" {
+		t.Errorf("reading a link inside the root wrong. got=%q, err=%v", data, err)
+	}
+	if _, err := fsys.ReadDir("sub/self/self"); err != nil {
+		t.Errorf("listing through a link inside the root returned error: %s", err)
+	}
+	if _, err := fsys.Stat("loop"); err == nil {
+		t.Errorf("link to itself did not return an error")
+	}
+	if err := fsys.Remove("secret.txt"); err != nil {
+		t.Errorf("removing a link that leads out of the root returned error: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "secret.txt")); err != nil {
+		t.Errorf("removing a link removed the file it leads to")
+	}
+}
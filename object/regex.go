@@ -0,0 +1,76 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+// REGEX_OBJ is the type of compiled regular expressions
+const REGEX_OBJ = "REGEX"
+
+// Represents a compiled regular expression,
+// which can be bound to a name and reused
+// so its pattern is only compiled once
+type Regex struct {
+	Regexp *regexp.Regexp
+}
+
+// Returns the pattern the regular expression was compiled from
+func (r *Regex) Pattern() string {
+	return r.Regexp.String()
+}
+
+// Returns the string representation of the regular expression object
+func (r *Regex) Inspect() string {
+	return "regex(" + r.Pattern() + ")"
+}
+
+// Returns the type of the regular expression object
+// which is always a REGEX_OBJ
+func (r *Regex) Type() ObjectType {
+	return REGEX_OBJ
+}
+
+// Returns the hash key of the regular expression,
+// so regular expressions can be used as hash keys
+func (r *Regex) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(r.Pattern()))
+	return HashKey{Type: r.Type(), Value: h.Sum64()}
+}
+
+// Compares the patterns of the two regular expressions,
+// so regular expressions with the same pattern are equal
+func (r *Regex) Compare(other Object) (int, bool) {
+	o, ok := other.(*Regex)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(r.Pattern(), o.Pattern()), true
+}
+
+// Returns the property named by the string index, e.g. r.pattern.
+// pattern is the source of the regular expression and names
+// holds the names of its capture groups, with an empty
+// string for every group without a name
+func (r *Regex) Index(index Object) Object {
+	name, ok := index.(*String)
+	if !ok {
+		return &Error{Message: fmt.Sprintf("regex index must be STRING, got %s", index.Type())}
+	}
+	switch name.Value {
+	case "pattern":
+		return &String{Value: r.Pattern()}
+	case "names":
+		names := r.Regexp.SubexpNames()[1:]
+		elements := make([]Object, len(names))
+		for idx, n := range names {
+			elements[idx] = &String{Value: n}
+		}
+		return &Array{Elements: elements}
+	default:
+		return &Error{Message: fmt.Sprintf("regex has no property %s", name.Value)}
+	}
+}
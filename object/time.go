@@ -0,0 +1,226 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	TIME_OBJ     = "TIME"
+	DURATION_OBJ = "DURATION"
+)
+
+// Represents a point in time in a time zone
+type Time struct {
+	Value time.Time
+}
+
+// Represents the time elapsed between two points in time
+type Duration struct {
+	Value time.Duration
+}
+
+// Returns the string representation of the time object,
+// which is its RFC 3339 form, e.g. 2024-05-01T12:30:00Z
+func (t *Time) Inspect() string {
+	return t.Value.Format(time.RFC3339Nano)
+}
+
+// Returns the type of the time object
+// which is always a TIME_OBJ
+func (t *Time) Type() ObjectType {
+	return TIME_OBJ
+}
+
+// Returns the hash key of the time. Times that are the same
+// instant share a hash key, whatever their time zone
+func (t *Time) HashKey() HashKey {
+	return HashKey{Type: t.Type(), Value: uint64(t.Value.UnixNano())}
+}
+
+// Compares the two times by the instant they
+// stand for, ignoring their time zones
+func (t *Time) Compare(other Object) (int, bool) {
+	o, ok := other.(*Time)
+	if !ok {
+		return 0, false
+	}
+	return t.Value.Compare(o.Value), true
+}
+
+// Evaluates the infix operators of times.
+// A duration can be added to or subtracted from a time,
+// and subtracting two times returns the duration between them
+func (t *Time) InfixOperator(operator string, right Object) (Object, bool) {
+	switch right := right.(type) {
+	case *Duration:
+		switch operator {
+		case "+":
+			return &Time{Value: t.Value.Add(right.Value)}, true
+		case "-":
+			return &Time{Value: t.Value.Add(-right.Value)}, true
+		}
+	case *Time:
+		if operator == "-" {
+			return &Duration{Value: t.Value.Sub(right.Value)}, true
+		}
+	}
+	return nil, false
+}
+
+// Returns the part of the time named by the string index, e.g. t.year.
+// The parts are year, month (1 to 12), day, hour, minute, second,
+// nanosecond, weekday (0 for Sunday to 6 for Saturday), yearDay,
+// unix, unixMilli and zone
+func (t *Time) Index(index Object) Object {
+	name, ok := index.(*String)
+	if !ok {
+		return &Error{Message: fmt.Sprintf("time index must be STRING, got %s", index.Type())}
+	}
+	value := t.Value
+	switch name.Value {
+	case "year":
+		return &Integer{Value: int64(value.Year())}
+	case "month":
+		return &Integer{Value: int64(value.Month())}
+	case "day":
+		return &Integer{Value: int64(value.Day())}
+	case "hour":
+		return &Integer{Value: int64(value.Hour())}
+	case "minute":
+		return &Integer{Value: int64(value.Minute())}
+	case "second":
+		return &Integer{Value: int64(value.Second())}
+	case "nanosecond":
+		return &Integer{Value: int64(value.Nanosecond())}
+	case "weekday":
+		return &Integer{Value: int64(value.Weekday())}
+	case "yearDay":
+		return &Integer{Value: int64(value.YearDay())}
+	case "unix":
+		return &Integer{Value: value.Unix()}
+	case "unixMilli":
+		return &Integer{Value: value.UnixMilli()}
+	case "zone":
+		return &String{Value: value.Location().String()}
+	default:
+		return &Error{Message: fmt.Sprintf("time has no property %s", name.Value)}
+	}
+}
+
+// Returns the string representation of
+// the duration object, e.g. 1h30m0s
+func (d *Duration) Inspect() string {
+	return d.Value.String()
+}
+
+// Returns the type of the duration object
+// which is always a DURATION_OBJ
+func (d *Duration) Type() ObjectType {
+	return DURATION_OBJ
+}
+
+// Returns the hash key of the duration
+func (d *Duration) HashKey() HashKey {
+	return HashKey{Type: d.Type(), Value: uint64(d.Value)}
+}
+
+// Compares the lengths of the two durations
+func (d *Duration) Compare(other Object) (int, bool) {
+	o, ok := other.(*Duration)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case d.Value < o.Value:
+		return -1, true
+	case d.Value > o.Value:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// Evaluates the infix operators of durations. Durations can be
+// added to and subtracted from each other, multiplied and divided
+// by numbers, and divided by another duration, which returns
+// how many times it fits as a float
+func (d *Duration) InfixOperator(operator string, right Object) (Object, bool) {
+	switch right := right.(type) {
+	case *Duration:
+		switch operator {
+		case "+":
+			return &Duration{Value: d.Value + right.Value}, true
+		case "-":
+			return &Duration{Value: d.Value - right.Value}, true
+		case "/":
+			if right.Value == 0 {
+				return &Error{Message: "division by zero"}, true
+			}
+			return &Float{Value: float64(d.Value) / float64(right.Value)}, true
+		}
+	case *Time:
+		if operator == "+" {
+			return &Time{Value: right.Value.Add(d.Value)}, true
+		}
+	case *Integer, *Float:
+		factor, _ := ToFloat(right)
+		switch operator {
+		case "*":
+			return scaleDuration(float64(d.Value) * factor), true
+		case "/":
+			if factor == 0 {
+				return &Error{Message: "division by zero"}, true
+			}
+			return scaleDuration(float64(d.Value) / factor), true
+		}
+	}
+	return nil, false
+}
+
+// Evaluates the infix operators where the duration is the right
+// object, so a number times a duration works like the duration
+// times the number, e.g. 2 * time.HOUR
+func (d *Duration) ReflectedInfixOperator(operator string, left Object) (Object, bool) {
+	switch left.(type) {
+	case *Integer, *Float:
+		if operator == "*" {
+			return d.InfixOperator(operator, left)
+		}
+	}
+	return nil, false
+}
+
+// Returns the length of the duration in the unit named by the string
+// index, e.g. d.seconds. hours, minutes, seconds and milliseconds are
+// floats, and nanoseconds is an integer
+func (d *Duration) Index(index Object) Object {
+	name, ok := index.(*String)
+	if !ok {
+		return &Error{Message: fmt.Sprintf("duration index must be STRING, got %s", index.Type())}
+	}
+	switch name.Value {
+	case "hours":
+		return &Float{Value: d.Value.Hours()}
+	case "minutes":
+		return &Float{Value: d.Value.Minutes()}
+	case "seconds":
+		return &Float{Value: d.Value.Seconds()}
+	case "milliseconds":
+		return &Float{Value: float64(d.Value) / float64(time.Millisecond)}
+	case "nanoseconds":
+		return &Integer{Value: int64(d.Value)}
+	default:
+		return &Error{Message: fmt.Sprintf("duration has no property %s", name.Value)}
+	}
+}
+
+// scaleDuration returns the duration of the given number of
+// nanoseconds, or an error when it doesn't fit in a duration
+func scaleDuration(nanoseconds float64) Object {
+	if math.IsNaN(nanoseconds) || nanoseconds > math.MaxInt64 || nanoseconds < math.MinInt64 {
+		return &Error{Message: "duration out of range"}
+	}
+	return &Duration{Value: time.Duration(math.Round(nanoseconds))}
+}
@@ -16,6 +16,10 @@ type Parser struct {
 	errors          []string
 	prefixParseFunc map[token.TokenType]prefixParseFunc
 	infixParseFunc  map[token.TokenType]infixParseFunc
+
+	// how many block statements deep the parser is,
+	// used to only allow exports at the top level
+	depth int
 }
 
 type (
@@ -31,6 +35,7 @@ const (
 	_ int = iota
 
 	LOWEST
+	NULLISH     // ??
 	EQUALS      // ==
 	LESSGREATER // > or <
 	SUM         // +
@@ -42,16 +47,19 @@ const (
 
 // precedence map
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NE:       EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.NULLISH:      NULLISH,
+	token.EQ:           EQUALS,
+	token.NE:           EQUALS,
+	token.LT:           LESSGREATER,
+	token.GT:           LESSGREATER,
+	token.PLUS:         SUM,
+	token.MINUS:        SUM,
+	token.SLASH:        PRODUCT,
+	token.ASTERISK:     PRODUCT,
+	token.LPAREN:       CALL,
+	token.LBRACKET:     INDEX,
+	token.QUESTION_DOT: INDEX,
+	token.DOT:          INDEX,
 }
 
 // Initializes a new parser
@@ -71,11 +79,13 @@ func New(l *lexer.Lexer) *Parser {
 	// register the prefix parser functions
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.NULL, p.parseNullLiteral)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
@@ -96,6 +106,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.NULLISH, p.parseInfixExpression)
+	p.registerInfix(token.QUESTION_DOT, p.parseOptionalChain)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 
 	return p
 }
@@ -132,6 +145,10 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseVarStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -190,6 +207,103 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+// Parses an import statement, which is either
+// import "lib.kev" as lib or import { a, b as c } from "lib.kev"
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	// construct the import statement node
+	stmt := &ast.ImportStatement{Token: p.currentToken}
+
+	if p.peekTokenIs(token.LBRACE) {
+		// selective import, the names come before the path
+		p.nextToken()
+		stmt.Bindings = p.parseImportBindings()
+		if stmt.Bindings == nil {
+			return nil
+		}
+		if !p.expectPeek(token.FROM) || !p.expectPeek(token.STRING) {
+			return nil
+		}
+		stmt.Path = p.currentToken.Literal
+	} else {
+		// whole module import, the path comes before the name
+		if !p.expectPeek(token.STRING) {
+			return nil
+		}
+		stmt.Path = p.currentToken.Literal
+		if !p.expectPeek(token.AS) || !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	}
+
+	// check if the next token is a semicolon
+	for p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// Parses the names between the braces of a selective import,
+// each of which can be renamed with 'as'
+func (p *Parser) parseImportBindings() []*ast.ImportBinding {
+	bindings := []*ast.ImportBinding{}
+
+	if p.peekTokenIs(token.RBRACE) {
+		p.errors = append(p.errors, "expected at least one name to import")
+		return nil
+	}
+
+	for {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		name := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		binding := &ast.ImportBinding{Name: name, Alias: name}
+
+		if p.peekTokenIs(token.AS) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			binding.Alias = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		}
+		bindings = append(bindings, binding)
+
+		if p.peekTokenIs(token.RBRACE) {
+			p.nextToken()
+			return bindings
+		}
+		if !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+}
+
+// Parses an export statement, e.g. export var x = 1.
+// Exports are only allowed at the top level of a program
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	// construct the export statement node
+	stmt := &ast.ExportStatement{Token: p.currentToken}
+
+	if p.depth > 0 {
+		p.errors = append(p.errors, "export is only allowed at the top level")
+		return nil
+	}
+
+	// check if the next token is a var statement
+	if !p.expectPeek(token.VAR) {
+		return nil
+	}
+
+	stmt.Statement = p.parseVarStatement()
+	if stmt.Statement == nil {
+		return nil
+	}
+
+	return stmt
+}
+
 // Parses an expression
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	// get the prefix parser function for the current token
@@ -268,6 +382,25 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+// Parses a Float Literal
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	// construct the float literal node
+	lit := &ast.FloatLiteral{Token: p.currentToken}
+
+	// parse the literal into a float64
+	value, err := strconv.ParseFloat(p.currentToken.Literal, 64)
+	if err != nil {
+		msg := "could not parse " + p.currentToken.Literal + " as float"
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	// set the value of the float literal node
+	lit.Value = value
+
+	return lit
+}
+
 // Parses a String Literal
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}
@@ -330,6 +463,11 @@ func (p *Parser) parseBoolean() ast.Expression {
 	return exp
 }
 
+// Parses the null literal
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.currentToken}
+}
+
 // Parses an if expression
 func (p *Parser) parseIfExpression() ast.Expression {
 	// construct the if expression node
@@ -385,6 +523,10 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	// construct the block statement node
 	block := &ast.BlockStatement{Token: p.currentToken}
 
+	// keep track of how deep the blocks are nested
+	p.depth++
+	defer func() { p.depth-- }()
+
 	// initialize the statements array
 	block.Statements = []ast.Statement{}
 
@@ -486,11 +628,80 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	return exp
 }
 
+// parses a member expression, e.g. lib.name
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.currentToken, Object: left}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	return exp
+}
+
+// parses an optional member, index, slice or call expression,
+// e.g. hash?.key, hash?.["key"] or fn?.(x), which evaluate to
+// null instead of failing when the left side is null
+func (p *Parser) parseOptionalChain(left ast.Expression) ast.Expression {
+	switch {
+	case p.peekTokenIs(token.IDENT):
+		exp, ok := p.parseMemberExpression(left).(*ast.MemberExpression)
+		if !ok {
+			return nil
+		}
+		exp.Optional = true
+		return exp
+	case p.peekTokenIs(token.LBRACKET):
+		p.nextToken()
+		switch exp := p.parseIndexExpression(left).(type) {
+		case *ast.IndexExpression:
+			exp.Optional = true
+			return exp
+		case *ast.SliceExpression:
+			exp.Optional = true
+			return exp
+		}
+		return nil
+	case p.peekTokenIs(token.LPAREN):
+		p.nextToken()
+		exp := p.parseCallExpression(left).(*ast.CallExpression)
+		exp.Optional = true
+		return exp
+	default:
+		msg := "expected a name, [ or ( after ?., got " + string(p.peekToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+}
+
+// parses an index expression, e.g. arr[1], or a slice
+// expression when the brackets hold a colon, e.g. arr[1:3].
+// Both sides of the colon can be left out, e.g. arr[:2] or arr[2:]
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.currentToken, Left: left}
+	tok := p.currentToken
+
+	p.nextToken()
+	var start ast.Expression
+	if !p.currentTokenIs(token.COLON) {
+		start = p.parseExpression(LOWEST)
+		if !p.peekTokenIs(token.COLON) {
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+			return &ast.IndexExpression{Token: tok, Left: left, Index: start}
+		}
+		p.nextToken()
+	}
+
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		return exp
+	}
 
 	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	exp.End = p.parseExpression(LOWEST)
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
@@ -511,6 +722,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
 		}
@@ -147,6 +147,30 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestFloatLiteralExpression(t *testing.T) {
+	input := "2.5;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	literal, ok := stmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != 2.5 {
+		t.Errorf("literal.Value not %f. got=%f", 2.5, literal.Value)
+	}
+	if literal.TokenLiteral() != "2.5" {
+		t.Errorf("literal.TokenLiteral not %s. got=%s", "2.5", literal.TokenLiteral())
+	}
+}
+
 func TestStringLiteralExpression(t *testing.T) {
 	input := `"hello world";`
 	l := lexer.New(input)
@@ -279,6 +303,13 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
 		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
 		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+		{"a ?? b == c", "(a ?? (b == c))"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"null", "null"},
+		{"a?.[b]?.[c]", "((a?.[b])?.[c])"},
+		{"a?.[1:]", "(a?.[1:])"},
+		{"f?.(x, y) + 1", "(f?.(x, y) + 1)"},
+		{"h?.[k] ?? 0", "((h?.[k]) ?? 0)"},
 	}
 
 	for _, tt := range tests {
@@ -610,6 +641,132 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"myArray[1:3]", "(myArray[1:3])"},
+		{"myArray[:2]", "(myArray[:2])"},
+		{"myArray[2:]", "(myArray[2:])"},
+		{"myArray[:]", "(myArray[:])"},
+		{"myArray[-2:len(myArray)]", "(myArray[(-2):len(myArray)])"},
+		{"myArray[1 + 1:][0]", "((myArray[(1 + 1):])[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, stmt.Expression.String())
+		}
+	}
+
+	l := lexer.New("myArray[1:]")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	sliceExp, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.SliceExpression)
+	if !ok {
+		t.Fatalf("exp is not ast.SliceExpression. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	if !testIntegerLiteral(t, sliceExp.Start, 1) {
+		return
+	}
+	if sliceExp.End != nil {
+		t.Errorf("sliceExp.End is not nil. got=%s", sliceExp.End)
+	}
+}
+
+func TestOptionalChainErrors(t *testing.T) {
+	l := lexer.New("a?.1")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors for a?.1")
+	}
+	if errors[0] != "expected a name, [ or ( after ?., got INT" {
+		t.Errorf("wrong error message. got=%q", errors[0])
+	}
+}
+
+func TestImportAndExportStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib.kev" as lib`, `import "lib.kev" as lib;`},
+		{`import "path/to/lib.kev" as lib;`, `import "path/to/lib.kev" as lib;`},
+		{`import { a } from "lib.kev"`, `import { a } from "lib.kev";`},
+		{`import { a, b as c } from "lib.kev";`, `import { a, b as c } from "lib.kev";`},
+		{`export var x = 1 + 2;`, `export var x = (1 + 2);`},
+		{`lib.name`, `(lib.name)`},
+		{`lib.f(1).g`, `((lib.f)(1).g)`},
+		{`a.b[0]`, `((a.b)[0])`},
+		{`a?.b.c`, `((a?.b).c)`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	l := lexer.New(`import { a, b as c } from "lib.kev"`)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt, ok := program.Statements[0].(*ast.ImportStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ImportStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Path != "lib.kev" || stmt.Alias != nil || len(stmt.Bindings) != 2 {
+		t.Fatalf("import statement wrong. got=%+v", stmt)
+	}
+	if stmt.Bindings[1].Name.Value != "b" || stmt.Bindings[1].Alias.Value != "c" {
+		t.Errorf("binding wrong. got=%s as %s", stmt.Bindings[1].Name, stmt.Bindings[1].Alias)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib.kev"`, "expected next token to be AS, got EOF"},
+		{`import lib`, "expected next token to be STRING, got IDENT"},
+		{`import {} from "lib.kev"`, "expected at least one name to import"},
+		{`import { a } "lib.kev"`, "expected next token to be FROM, got STRING"},
+		{`export 1`, "expected next token to be VAR, got INT"},
+		{`var f = func() { export var x = 1; }`, "export is only allowed at the top level"},
+		{`lib.1`, "expected next token to be IDENT, got INT"},
+	}
+	for _, tt := range errorTests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if p.Errors()[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors()[0])
+		}
+	}
+}
+
 func TestParsingHashLiteralsStringKeys(t *testing.T) {
 	input := `{"one": 1, "two": 2, "three": 3}`
 	l := lexer.New(input)
@@ -628,6 +785,9 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 	if len(hash.Pairs) != 3 {
 		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
 	}
+	if hash.String() != `{one:1, two:2, three:3}` {
+		t.Errorf("hash.Keys not in written order. got=%s", hash.String())
+	}
 	expected := map[string]int64{
 		"one":   1,
 		"two":   2,
@@ -1,7 +1,6 @@
 package repl
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"os"
@@ -27,36 +26,73 @@ const (
 
 const PROMPT = ">> "
 
-func RunFile(fileName string) {
-	contents, err := os.ReadFile(fileName)
+// RunFile evaluates the file and returns the status the process
+// should exit with, like RunSource. The file name - reads the
+// script from the context's stdin instead
+func RunFile(ctx *object.Context, fileName string) int {
+	var contents []byte
+	var err error
+	if fileName == "-" {
+		contents, err = io.ReadAll(ctx.Stdin)
+		fileName = ""
+	} else {
+		contents, err = os.ReadFile(fileName)
+	}
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(ctx.Stderr, err)
+		return 1
 	}
+	return RunSource(ctx, string(contents), fileName)
+}
 
+// RunSource evaluates the source with the context, which holds the
+// arguments passed to the script, and writes the result to the
+// context's stdout. Imports in the source are resolved relative to
+// the file it came from, or the working directory when fileName is
+// empty. It returns the status the process should exit with. That is
+// the status passed to exit, 1 when the source couldn't be parsed or
+// evaluating it ended in an error, and 0 otherwise
+func RunSource(ctx *object.Context, src, fileName string) int {
 	env := object.NewEnvironment()
-	l := lexer.New(string(contents))
+	env.SetContext(ctx)
+	env.SetFile(fileName)
+	l := lexer.New(src)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.Errors()) != 0 {
-		printParserErrors(os.Stdout, p.Errors())
-		return
+		printParserErrors(ctx.Stdout, p.Errors())
+		return 1
 	}
 	lastEvaluated := evaluator.Eval(program, env)
+	if errObj, ok := lastEvaluated.(*object.Error); ok && errObj.Exit {
+		return errObj.Code
+	}
 	if lastEvaluated != nil {
-		io.WriteString(os.Stdout, lastEvaluated.Inspect()+"\n")
+		io.WriteString(ctx.Stdout, lastEvaluated.Inspect()+"\n")
+	}
+	if lastEvaluated != nil && lastEvaluated.Type() == object.ERROR_OBJ {
+		return 1
 	}
+	return 0
 }
 
 func RunPrompt(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
 	env := object.NewEnvironment()
+
+	// builtins like print write to the same place the repl writes
+	// its results to, and the prompt reads its lines through the
+	// context, so builtins like input read from the same buffer
+	ctx := object.NewContext()
+	ctx.Stdout = out
+	ctx.Stderr = out
+	ctx.Stdin = in
+	env.SetContext(ctx)
 	for {
 		fmt.Fprint(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		line, err := ctx.ReadLine()
+		if err != nil {
 			return
 		}
-		line := scanner.Text()
 		l := lexer.New(line)
 		p := parser.New(l)
 		program := p.ParseProgram()
@@ -65,6 +101,11 @@ func RunPrompt(in io.Reader, out io.Writer) {
 			continue
 		}
 		evaluated := evaluator.Eval(program, env)
+
+		// exit ends the repl
+		if errObj, ok := evaluated.(*object.Error); ok && errObj.Exit {
+			return
+		}
 		if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect()+"\n")
 		}
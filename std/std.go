@@ -0,0 +1,64 @@
+package std
+
+import (
+	"embed"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// files holds the standard library modules, which are written
+// in kev and embedded in the binary so they can be imported
+// with import "std/<name>" without any files on disk.
+// Every module has its self-tests in <name>_test.kev
+//
+//go:embed *.kev
+var files embed.FS
+
+// testSuffix ends the name of the file
+// holding the self-tests of a module
+const testSuffix = "_test"
+
+// Returns the source of the std module with the given name,
+// e.g. "strings". It returns false when there is no such module
+func Source(name string) ([]byte, bool) {
+	if name == "" || strings.HasSuffix(name, testSuffix) {
+		return nil, false
+	}
+	return read(name)
+}
+
+// Returns the source of the self-tests of the std module
+// with the given name. It returns false when there are none
+func Tests(name string) ([]byte, bool) {
+	return read(name + testSuffix)
+}
+
+// Returns the names of every std module in alphabetical order
+func Names() []string {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".kev")
+		if !strings.HasSuffix(name, testSuffix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// read returns the source of the embedded file with the name
+func read(name string) ([]byte, bool) {
+	if strings.ContainsAny(name, `/\.`) {
+		return nil, false
+	}
+	src, err := files.ReadFile(name + ".kev")
+	if err != nil {
+		return nil, false
+	}
+	return src, true
+}
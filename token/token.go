@@ -5,10 +5,12 @@ const (
 	EOF       = "EOF"
 	IDENT     = "IDENT"
 	INT       = "INT"
+	FLOAT     = "FLOAT"
 	ASSIGN    = "="
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
 	LPAREN    = "("
 	RPAREN    = ")"
 	LBRACE    = "{"
@@ -21,6 +23,10 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
+	AS       = "AS"
+	FROM     = "FROM"
 
 	PLUS     = "+"
 	MINUS    = "-"
@@ -32,10 +38,14 @@ const (
 
 	TRUE  = "TRUE"
 	FALSE = "FALSE"
+	NULL  = "NULL"
 
 	EQ = "=="
 	NE = "!="
 
+	NULLISH      = "??"
+	QUESTION_DOT = "?."
+
 	STRING = "STRING"
 )
 
@@ -52,8 +62,13 @@ var keywords = map[string]TokenType{
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+	"import": IMPORT,
+	"export": EXPORT,
+	"as":     AS,
+	"from":   FROM,
 	"true":   TRUE,
 	"false":  FALSE,
+	"null":   NULL,
 }
 
 // checks if the identifier is a keyword or not